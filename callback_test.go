@@ -8,8 +8,13 @@ package purego_test
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"unsafe"
 
@@ -154,6 +159,581 @@ func TestNewCallbackFloat32AndFloat64(t *testing.T) {
 	}
 }
 
+func TestNewCallbackFloatReturn(t *testing.T) {
+	var fn64 func(float64, int) float64
+	purego.RegisterFunc(&fn64, purego.NewCallback(func(x float64, n int) float64 {
+		return x * float64(n)
+	}))
+	if got, want := fn64(1.25, 3), 3.75; got != want {
+		t.Errorf("float64 callback got %v want %v", got, want)
+	}
+
+	var fn32 func(float32, int) float32
+	purego.RegisterFunc(&fn32, purego.NewCallback(func(x float32, n int) float32 {
+		return x * float32(n)
+	}))
+	if got, want := fn32(-1.5, 3), float32(-4.5); got != want {
+		t.Errorf("float32 callback got %v want %v", got, want)
+	}
+}
+
+func TestCallbackFloatReturnFromC(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+
+	var callDoubleCallback func(cb uintptr, x float64, n int32) float64
+	purego.RegisterLibFunc(&callDoubleCallback, lib, "callDoubleCallback")
+	var callFloatCallback func(cb uintptr, x float32, n int32) float32
+	purego.RegisterLibFunc(&callFloatCallback, lib, "callFloatCallback")
+
+	cbDouble := purego.NewCallback(func(x float64, n int32) float64 {
+		return x * float64(n)
+	})
+	if got, want := callDoubleCallback(cbDouble, 2.25, 4), 9.5; got != want {
+		t.Errorf("callDoubleCallback() = %v, want %v", got, want)
+	}
+
+	cbFloat := purego.NewCallback(func(x float32, n int32) float32 {
+		return x * float32(n)
+	})
+	if got, want := callFloatCallback(cbFloat, 1.5, -3), float32(-4); got != want {
+		t.Errorf("callFloatCallback() = %v, want %v", got, want)
+	}
+}
+
+func TestCallbackStringAndSliceArgs(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+
+	var callLogCallback func(cb uintptr, level int32, msg string)
+	purego.RegisterLibFunc(&callLogCallback, lib, "callLogCallback")
+	var callSumCallback func(cb uintptr) int32
+	purego.RegisterLibFunc(&callSumCallback, lib, "callSumCallback")
+
+	var gotLevel int32
+	var gotMsg string
+	cbLog := purego.NewCallback(func(level int32, msg string) {
+		gotLevel, gotMsg = level, msg
+	})
+	callLogCallback(cbLog, 3, "hello from C")
+	if gotLevel != 3 || gotMsg != "hello from C" {
+		t.Errorf("log callback got (%d, %q) want (3, %q)", gotLevel, gotMsg, "hello from C")
+	}
+
+	cbSum := purego.NewCallback(func(values []int32) int32 {
+		var sum int32
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	})
+	if got, want := callSumCallback(cbSum), int32(15); got != want {
+		t.Errorf("callSumCallback() = %d, want %d", got, want)
+	}
+}
+
+func TestReleaseCallback(t *testing.T) {
+	cb1 := purego.NewCallback(func() int { return 1 })
+	purego.ReleaseCallback(cb1)
+	cb2 := purego.NewCallback(func() int { return 2 })
+	defer purego.ReleaseCallback(cb2)
+	if cb1 != cb2 {
+		t.Errorf("NewCallback did not reuse the released slot: got %#x want %#x", cb2, cb1)
+	}
+	var fn func() int
+	purego.RegisterFunc(&fn, cb2)
+	if got := fn(); got != 2 {
+		t.Errorf("released slot called the old function: got %d want 2", got)
+	}
+
+	// Creating more callbacks than the maximum must not fail when they are released.
+	for i := range 3000 {
+		cb := purego.NewCallback(func() int { return i })
+		purego.ReleaseCallback(cb)
+	}
+
+	cb3 := purego.NewCallback(func() int { return 3 })
+	purego.ReleaseCallback(cb3)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("ReleaseCallback of a released callback did not panic")
+			}
+		}()
+		purego.ReleaseCallback(cb3)
+	}()
+}
+
+func TestCallbackConcurrentCalls(t *testing.T) {
+	cb := purego.NewCallback(func(a, b int) int { return a + b })
+	defer purego.ReleaseCallback(cb)
+	var fn func(a, b int) int
+	purego.RegisterFunc(&fn, cb)
+
+	// Calls must not be disturbed by callbacks that are created and released at the same time.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 1000 {
+			other := purego.NewCallback(func() int { return i })
+			purego.ReleaseCallback(other)
+		}
+	}()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				if got, want := fn(g, i), g+i; got != want {
+					t.Errorf("goroutine %d: got %d want %d", g, got, want)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	<-done
+}
+
+func TestFuncArgumentCallbackReused(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+
+	var callCallback func(fn func(cstr *byte, n int) int, s string) int
+	purego.RegisterLibFunc(&callCallback, lib, "callCallback")
+
+	var total int
+	goFunc := func(cstr *byte, n int) int {
+		total += n
+		return 1
+	}
+	// Passing the same function value more times than the maximum number of callbacks must not
+	// use up a callback each time.
+	const count = 3000
+	for i := range count {
+		if got, want := callCallback(goFunc, "abc"), 10101; got != want {
+			t.Fatalf("%d: callCallback() got %v want %v", i, got, want)
+		}
+	}
+	if want := count * 3; total != want {
+		t.Errorf("callback called with a total length of %d, want %d", total, want)
+	}
+}
+
+func TestScopedCallback(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	sym, err := purego.Dlsym(lib, "callCallback")
+	if err != nil {
+		t.Fatalf("Dlsym(callCallback) failed: %v", err)
+	}
+
+	type callbackFunc = func(cstr *byte, n int) int
+	var callCallback func(fn purego.ScopedCallback[callbackFunc], s string) int
+	purego.RegisterFunc(&callCallback, sym)
+	callCallbackTyped := purego.NewFunc2[int, purego.ScopedCallback[callbackFunc], string](sym)
+	var callCallbackAny func(args ...any) int
+	purego.RegisterFunc(&callCallbackAny, sym)
+
+	// A func argument is kept after the call since C may hold onto it.
+	var registerHandler func(fn func(n int) int)
+	purego.RegisterLibFunc(&registerHandler, lib, "registerHandler")
+	var callRegisteredHandler func(n int) int
+	purego.RegisterLibFunc(&callRegisteredHandler, lib, "callRegisteredHandler")
+	offset := 1000
+	registerHandler(func(n int) int { return n + offset })
+
+	// Passing a new closure for every call more times than the maximum number of callbacks
+	// must release the callback of each call once it returns.
+	const count = 3000
+	var total int
+	for i := range count {
+		fn := purego.Scoped(func(cstr *byte, n int) int {
+			total += i
+			return 1
+		})
+		if got, want := callCallback(fn, "abc"), 10101; got != want {
+			t.Fatalf("%d: callCallback() got %v want %v", i, got, want)
+		}
+		if got, want := callCallbackTyped(fn, "abc"), 10101; got != want {
+			t.Fatalf("%d: NewFunc2 callCallback() got %v want %v", i, got, want)
+		}
+		if got, want := callCallbackAny(fn, "abc"), 10101; got != want {
+			t.Fatalf("%d: callCallback(...any) got %v want %v", i, got, want)
+		}
+	}
+	if want := 3 * count * (count - 1) / 2; total != want {
+		t.Errorf("callbacks called with a total of %d, want %d", total, want)
+	}
+	if got := callRegisteredHandler(5); got != 1005 {
+		t.Errorf("callRegisteredHandler(5) = %d after the scoped calls, want 1005", got)
+	}
+}
+
+func TestNewClosure(t *testing.T) {
+	if (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") || (runtime.GOOS == "darwin" && runtime.GOARCH == "arm64") {
+		t.Skip("closures are not supported on " + runtime.GOOS + "/" + runtime.GOARCH)
+	}
+
+	type conn struct{ id int }
+	handler := func(c *conn, n int) int {
+		return c.id*1000 + n
+	}
+	// Create more closures than the number of callbacks NewCallback can create.
+	const count = 3000
+	cls := make([]*purego.Closure, count)
+	for i := range cls {
+		cls[i] = purego.NewClosure(handler, &conn{id: i})
+	}
+	for i, c := range cls {
+		var fn func(n int) int
+		purego.RegisterFunc(&fn, c.Ptr())
+		if got, want := fn(7), i*1000+7; got != want {
+			t.Fatalf("closure %d: got %d want %d", i, got, want)
+		}
+	}
+	for _, c := range cls {
+		c.Close()
+		if c.Ptr() != 0 {
+			t.Errorf("Ptr() after Close = %#x, want 0", c.Ptr())
+		}
+	}
+
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	var callSumCallback func(cb uintptr) int32
+	purego.RegisterLibFunc(&callSumCallback, lib, "callSumCallback")
+
+	scaled := func(scale int32, values []int32) int32 {
+		var sum int32
+		for _, v := range values {
+			sum += v
+		}
+		return sum * scale
+	}
+	c2 := purego.NewClosure(scaled, int32(2))
+	defer c2.Close()
+	c3 := purego.NewClosure(scaled, int32(3))
+	defer c3.Close()
+	if got, want := callSumCallback(c2.Ptr()), int32(30); got != want {
+		t.Errorf("callSumCallback(c2) = %d, want %d", got, want)
+	}
+	if got, want := callSumCallback(c3.Ptr()), int32(45); got != want {
+		t.Errorf("callSumCallback(c3) = %d, want %d", got, want)
+	}
+}
+
+func TestSetCallbackPanicHandler(t *testing.T) {
+	var recovered any
+	purego.SetCallbackPanicHandler(func(v any) uintptr {
+		recovered = v
+		return 42
+	})
+	defer purego.SetCallbackPanicHandler(nil)
+
+	cb := purego.NewCallback(func(n int) int {
+		if n < 0 {
+			panic("negative")
+		}
+		return n
+	})
+	defer purego.ReleaseCallback(cb)
+	var fn func(n int) int
+	purego.RegisterFunc(&fn, cb)
+	if got := fn(1); got != 1 {
+		t.Errorf("fn(1) = %d, want 1", got)
+	}
+	if recovered != nil {
+		t.Errorf("handler called without a panic: %v", recovered)
+	}
+	if got := fn(-1); got != 42 {
+		t.Errorf("fn(-1) = %d, want 42", got)
+	}
+	if recovered != "negative" {
+		t.Errorf("handler got %v, want %q", recovered, "negative")
+	}
+
+	ok := purego.NewCallback(func(n int) bool {
+		panic("bool")
+	})
+	defer purego.ReleaseCallback(ok)
+	var okFn func(n int) bool
+	purego.RegisterFunc(&okFn, ok)
+	if !okFn(1) {
+		t.Errorf("okFn(1) = false, want true")
+	}
+
+	half := purego.NewCallback(func(x float64) float64 {
+		panic("float")
+	})
+	defer purego.ReleaseCallback(half)
+	var halfFn func(x float64) float64
+	purego.RegisterFunc(&halfFn, half)
+	if got := halfFn(1); got != 0 {
+		t.Errorf("halfFn(1) = %v, want 0", got)
+	}
+
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		return
+	}
+	// Structs that are returned in registers and in memory are zero.
+	type pair struct{ A, B int64 }
+	type triple struct{ A, B, C int64 }
+	pairCb := purego.NewCallback(func(n int64) pair {
+		panic("pair")
+	})
+	defer purego.ReleaseCallback(pairCb)
+	var pairFn func(n int64) pair
+	purego.RegisterFunc(&pairFn, pairCb)
+	if got := pairFn(1); got != (pair{}) {
+		t.Errorf("pairFn(1) = %+v, want zero", got)
+	}
+	tripleCb := purego.NewCallback(func(n int64) triple {
+		panic("triple")
+	})
+	defer purego.ReleaseCallback(tripleCb)
+	var tripleFn func(n int64) triple
+	purego.RegisterFunc(&tripleFn, tripleCb)
+	if got := tripleFn(1); got != (triple{}) {
+		t.Errorf("tripleFn(1) = %+v, want zero", got)
+	}
+	if recovered != "triple" {
+		t.Errorf("handler got %v, want %q", recovered, "triple")
+	}
+}
+
+func TestCallbackGoexit(t *testing.T) {
+	if os.Getenv("PUREGO_TEST_CALLBACK_GOEXIT") == "1" {
+		var fn func()
+		purego.RegisterFunc(&fn, purego.NewCallback(func() { runtime.Goexit() }))
+		fn()
+		return
+	}
+	// runtime.Goexit in a callback crashes the process, so it is run in a child process.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCallbackGoexit$")
+	cmd.Env = append(os.Environ(), "PUREGO_TEST_CALLBACK_GOEXIT=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("runtime.Goexit in a callback did not crash:\n%s", out)
+	}
+	if want := "fatal error: purego: runtime.Goexit called in a callback"; !strings.Contains(string(out), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+}
+
+func TestCallbackFromForeignThread(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	var callCallbackFromThreads func(cb uintptr, numThreads, calls int32) int32
+	purego.RegisterLibFunc(&callCallbackFromThreads, lib, "callCallbackFromThreads")
+
+	cb := purego.NewCallback(func(i int32) int32 {
+		// Allocate and yield so that the runtime has to know the calling thread.
+		s := make([]int32, i+1)
+		runtime.Gosched()
+		return int32(len(s))
+	})
+	defer purego.ReleaseCallback(cb)
+
+	const numThreads, calls = 8, 100
+	for range 3 {
+		got := callCallbackFromThreads(cb, numThreads, calls)
+		if want := int32(numThreads * calls * (calls + 1) / 2); got != want {
+			t.Fatalf("callCallbackFromThreads() = %d, want %d", got, want)
+		}
+	}
+}
+
+func TestCallbackForeignThreadKeepsM(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "libcbtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "libcbtest", "callback_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := purego.Dlopen(libFileName, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	var startThread func(cb uintptr) int32
+	purego.RegisterLibFunc(&startThread, lib, "startThread")
+	var stepThread func(n int32) int32
+	purego.RegisterLibFunc(&stepThread, lib, "stepThread")
+	var stopThread func()
+	purego.RegisterLibFunc(&stopThread, lib, "stopThread")
+
+	// The goroutine of an M bound to a C thread counts as a goroutine until the M is dropped.
+	var goids []string
+	cb := purego.NewCallback(func(n int32) int32 {
+		buf := make([]byte, 64)
+		buf = buf[:runtime.Stack(buf, false)]
+		goid, _, _ := strings.Cut(strings.TrimPrefix(string(buf), "goroutine "), " ")
+		goids = append(goids, goid)
+		return n + 1
+	})
+	defer purego.ReleaseCallback(cb)
+
+	// runtime/cgo only binds Ms to C threads once a function exported with cgo was called,
+	// so the M is kept only by internal/fakecgo.
+	bound := !cgoEnabled()
+	before := runtime.NumGoroutine()
+	if r := startThread(cb); r != 0 {
+		t.Fatalf("startThread() = %d", r)
+	}
+	for i := range int32(3) {
+		if got := stepThread(i); got != i+1 {
+			t.Fatalf("stepThread(%d) = %d, want %d", i, got, i+1)
+		}
+		if got := runtime.NumGoroutine(); bound && got != before+1 {
+			t.Errorf("after callback %d: NumGoroutine() = %d, want %d as the thread keeps its M", i, got, before+1)
+		}
+	}
+	if bound && !slices.Equal(goids, []string{goids[0], goids[0], goids[0]}) {
+		t.Errorf("the callbacks ran on goroutines %q, want the same one", goids)
+	}
+	stopThread()
+	// The M is dropped by the destructor of the thread's key, which runs before the thread is joined.
+	if got := runtime.NumGoroutine(); got != before {
+		t.Errorf("after the thread exited: NumGoroutine() = %d, want %d", got, before)
+	}
+}
+
+// cgoEnabled reports whether the test binary was built with cgo.
+func cgoEnabled() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, s := range info.Settings {
+		if s.Key == "CGO_ENABLED" {
+			return s.Value == "1"
+		}
+	}
+	return false
+}
+
+func TestCallbackScalarSignatures(t *testing.T) {
+	// Callbacks that only take and return integers, bools, pointers and floating-point numbers
+	// are called without reflect.Value.Call where the Go internal ABI allows it.
+	var called []int
+	noArgs := purego.NewCallback(func() { called = append(called, 0) })
+	defer purego.ReleaseCallback(noArgs)
+	purego.SyscallN(noArgs)
+
+	sub := purego.NewCallback(func(a, b int) int { return a - b })
+	defer purego.ReleaseCallback(sub)
+	var subFn func(a, b int) int
+	purego.RegisterFunc(&subFn, sub)
+	if got := subFn(2, 5); got != -3 {
+		t.Errorf("sub(2, 5) = %d, want -3", got)
+	}
+
+	mix := purego.NewCallback(func(a1, a2, a3, a4, a5 uintptr) uintptr { return a1 ^ a2<<8 ^ a3<<16 ^ a4<<24 ^ a5<<28 })
+	defer purego.ReleaseCallback(mix)
+	var mixFn func(a1, a2, a3, a4, a5 uintptr) uintptr
+	purego.RegisterFunc(&mixFn, mix)
+	if got, want := mixFn(1, 2, 3, 4, 5), uintptr(1^2<<8^3<<16^4<<24^5<<28); got != want {
+		t.Errorf("mix(1, 2, 3, 4, 5) = %#x, want %#x", got, want)
+	}
+
+	record := purego.NewCallback(func(a, b, c int64) { called = append(called, int(a+b+c)) })
+	defer purego.ReleaseCallback(record)
+	var recordFn func(a, b, c int64)
+	purego.RegisterFunc(&recordFn, record)
+	recordFn(1, 2, -4)
+	if want := []int{0, -1}; !slices.Equal(called, want) {
+		t.Errorf("callbacks recorded %v, want %v", called, want)
+	}
+
+	x := 7
+	mixed := purego.NewCallback(func(a int8, f float32, p *int, d float64, b bool, u uint16) float32 {
+		if !b {
+			return 0
+		}
+		return float32(a) + f + float32(*p) + float32(d) + float32(u)
+	})
+	defer purego.ReleaseCallback(mixed)
+	var mixedFn func(a int8, f float32, p *int, d float64, b bool, u uint16) float32
+	purego.RegisterFunc(&mixedFn, mixed)
+	if got := mixedFn(-3, 1.5, &x, 0.25, true, 1000); got != 1005.75 {
+		t.Errorf("mixed() = %v, want 1005.75", got)
+	}
+
+	narrow := purego.NewCallback(func(a int32, b uint8) int32 { return a * int32(b) })
+	defer purego.ReleaseCallback(narrow)
+	var narrowFn func(a int32, b uint8) int32
+	purego.RegisterFunc(&narrowFn, narrow)
+	if got := narrowFn(-5, 200); got != -1000 {
+		t.Errorf("narrow(-5, 200) = %d, want -1000", got)
+	}
+
+	less := purego.NewCallback(func(a, b unsafe.Pointer) bool { return *(*int)(a) < *(*int)(b) })
+	defer purego.ReleaseCallback(less)
+	var lessFn func(a, b *int) bool
+	purego.RegisterFunc(&lessFn, less)
+	y := 9
+	if !lessFn(&x, &y) || lessFn(&y, &x) {
+		t.Errorf("less(7, 9) = %v and less(9, 7) = %v, want true and false", lessFn(&x, &y), lessFn(&y, &x))
+	}
+
+	scale := purego.NewCallback(func(f1, f2, f3, f4, f5, f6, f7, f8, f9 float64, n int) float64 {
+		return (f1 + f2 + f3 + f4 + f5 + f6 + f7 + f8 + f9) * float64(n)
+	})
+	defer purego.ReleaseCallback(scale)
+	var scaleFn func(f1, f2, f3, f4, f5, f6, f7, f8, f9 float64, n int) float64
+	purego.RegisterFunc(&scaleFn, scale)
+	if got := scaleFn(1, 2, 3, 4, 5, 6, 7, 8, 9, -2); got != -90 {
+		t.Errorf("scale() = %v, want -90", got)
+	}
+}
+
 func ExampleNewCallback() {
 	cb := purego.NewCallback(func(a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15 int) int {
 		fmt.Println(a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15)
@@ -364,7 +944,6 @@ func TestCallbackFloat64StackPacking(t *testing.T) {
 	purego.RegisterLibFunc(&callCallback10Float64, lib, "callCallback10Float64")
 
 	// 10 float64s: 8 in registers, 2 on stack
-	// Return int64 since callbacks don't support float returns
 	goFunc := func(f1, f2, f3, f4, f5, f6, f7, f8, f9, f10 float64) int64 {
 		sum := f1 + f2 + f3 + f4 + f5 + f6 + f7 + f8 + f9 + f10
 		return int64(sum * 10) // 60.0 * 10 = 600
@@ -398,7 +977,6 @@ func TestCallbackFloat32StackPacking(t *testing.T) {
 	purego.RegisterLibFunc(&callCallback12Float32, lib, "callCallback12Float32")
 
 	// 12 float32s: 8 in registers, 4 on stack
-	// Return int64 since callbacks don't support float returns
 	goFunc := func(f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12 float32) int64 {
 		sum := f1 + f2 + f3 + f4 + f5 + f6 + f7 + f8 + f9 + f10 + f11 + f12
 		return int64(sum) // 78
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+// Closure is a C function pointer that calls a Go function together with a user data value.
+// Unlike the callbacks created by [NewCallback], the number of closures is not limited
+// because each closure is backed by a trampoline that is generated at runtime.
+type Closure struct {
+	ptr   uintptr
+	index uintptr
+}
+
+// NewClosure returns a Closure that calls fn with userData as its first argument followed by
+// the arguments passed by C. The remaining arguments and the result of fn follow the same rules
+// as the function passed to [NewCallback]. userData must be assignable to the type of the first
+// argument of fn. A nil userData stands for the zero value of that type.
+//
+// Many closures may share the same fn and differ only by userData. A Closure is not released
+// automatically and must be released with [Closure.Close] once C code no longer calls it.
+//
+// NewClosure is supported on amd64 and arm64 except on Windows and on Darwin ARM64, where executable
+// memory cannot be created without the JIT entitlement. It panics on other platforms.
+func NewClosure(fn any, userData any) *Closure {
+	ptr, index := compileClosure(fn, userData)
+	return &Closure{ptr: ptr, index: index}
+}
+
+// Ptr returns the C function pointer of c. It returns 0 after c is closed.
+func (c *Closure) Ptr() uintptr {
+	return c.ptr
+}
+
+// Close releases c so that its trampoline can be reused by a later call to [NewClosure].
+// The caller must ensure that C code no longer calls the pointer returned by [Closure.Ptr].
+// Calling Close more than once has no effect.
+func (c *Closure) Close() {
+	if c.ptr == 0 {
+		return
+	}
+	releaseClosure(c.index)
+	c.ptr = 0
+}
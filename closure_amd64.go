@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd
+
+package purego
+
+import "encoding/binary"
+
+const closureTrampolineSize = 32
+
+func closureSupported() bool {
+	return true
+}
+
+// writeClosureTrampoline writes the trampoline of the closure with the callback index to dst.
+// callbackasm1 computes the callback index from the return address pushed by an entry of
+// callbackasm, so the trampoline pushes the address that entry index would have pushed.
+func writeClosureTrampoline(dst []byte, index uintptr) {
+	dst[0], dst[1] = 0x48, 0xb8 // MOVQ $callbackasmAddr(index+1), AX
+	binary.LittleEndian.PutUint64(dst[2:], uint64(callbackasmAddr(int(index)+1)))
+	dst[10] = 0x50                // PUSHQ AX
+	dst[11], dst[12] = 0x48, 0xb8 // MOVQ $callbackasm1, AX
+	binary.LittleEndian.PutUint64(dst[13:], uint64(callbackasm1ABI0))
+	dst[21], dst[22] = 0xff, 0xe0 // JMP AX
+	for i := 23; i < len(dst); i++ {
+		dst[i] = 0xcc // INT3
+	}
+}
+
+// flushInstructionCache does nothing since the instruction cache is coherent on amd64.
+func flushInstructionCache(addr, size uintptr) {}
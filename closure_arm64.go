@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd
+
+package purego
+
+import "encoding/binary"
+
+const closureTrampolineSize = 32
+
+// closureSupported reports whether closures can be created. Darwin does not allow
+// writable memory to become executable without MAP_JIT and the JIT entitlement.
+func closureSupported() bool {
+	return !isDarwin
+}
+
+// writeClosureTrampoline writes the trampoline of the closure with the callback index to dst.
+// Like the entries of callbackasm, it loads the callback index into R12 and branches to callbackasm1.
+func writeClosureTrampoline(dst []byte, index uintptr) {
+	binary.LittleEndian.PutUint32(dst[0:], 0x5800008c)  // LDR 16(PC), R12
+	binary.LittleEndian.PutUint32(dst[4:], 0x580000b0)  // LDR 24(PC), R16
+	binary.LittleEndian.PutUint32(dst[8:], 0xd61f0200)  // BR (R16)
+	binary.LittleEndian.PutUint32(dst[12:], 0xd503201f) // NOOP
+	binary.LittleEndian.PutUint64(dst[16:], uint64(index))
+	binary.LittleEndian.PutUint64(dst[24:], uint64(callbackasm1ABI0))
+}
+
+// flushInstructionCache makes the instructions written to [addr, addr+size) visible to instruction fetches.
+// It is implemented in sys_unix_arm64.s.
+//
+//go:noescape
+func flushInstructionCache(addr, size uintptr)
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || freebsd || linux || netbsd) && !(amd64 || arm64)
+
+package purego
+
+// closureTrampolineSize is not zero so that allocClosures compiles, but it is never called.
+const closureTrampolineSize = 1
+
+func closureSupported() bool {
+	return false
+}
+
+func writeClosureTrampoline(dst []byte, index uintptr) {
+	panic("purego: writeClosureTrampoline should not be called")
+}
+
+func flushInstructionCache(addr, size uintptr) {
+	panic("purego: flushInstructionCache should not be called")
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || (linux && (386 || amd64 || arm || arm64 || loong64 || ppc64le || riscv64 || (s390x && (cgo || go1.27)))) || netbsd
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// callbackasm1 is implemented in sys_GOARCH.s or sys_unix_GOARCH.s
+//
+//go:linkname __callbackasm1 callbackasm1
+var __callbackasm1 byte
+var callbackasm1ABI0 = uintptr(unsafe.Pointer(&__callbackasm1))
+
+// closures holds the closures created by NewClosure. The callback index of the i-th closure
+// is maxCB+i so that it never collides with cbs.funcs. lock is only taken to create or release
+// a closure. Calling one reads pages without locking.
+var closures struct {
+	lock  sync.Mutex
+	pages atomic.Pointer[[]*closurePage] // replaced with a longer copy when a page is added
+	free  []int                          // the indices of unused closures
+}
+
+// closurePage is a page of trampolines and the closures they call.
+type closurePage struct {
+	callbacks []atomic.Pointer[callback]
+	ptrs      []uintptr // the trampoline of each closure
+}
+
+// closuresPerPage returns the number of trampolines in a closurePage.
+func closuresPerPage() int {
+	return syscall.Getpagesize() / closureTrampolineSize
+}
+
+func compileClosure(fn any, userData any) (ptr, index uintptr) {
+	if !closureSupported() {
+		panic("purego: NewClosure is not supported on " + runtime.GOOS + "/" + runtime.GOARCH)
+	}
+	val := callbackFunc(fn, 1)
+	ty := val.Type()
+	if ty.NumIn() == 0 {
+		panic("purego: the closure function must take the user data as its first argument")
+	}
+	data := reflect.Zero(ty.In(0))
+	if userData != nil {
+		data = reflect.ValueOf(userData)
+		if !data.Type().AssignableTo(ty.In(0)) {
+			panic("purego: user data of type " + data.Type().String() + " is not assignable to " + ty.In(0).String())
+		}
+	}
+	cb := newCallback(val, data, 1)
+	closures.lock.Lock()
+	defer closures.lock.Unlock()
+	if len(closures.free) == 0 {
+		allocClosures()
+	}
+	i := closures.free[len(closures.free)-1]
+	closures.free = closures.free[:len(closures.free)-1]
+	n := closuresPerPage()
+	page := (*closures.pages.Load())[i/n]
+	page.callbacks[i%n].Store(cb)
+	return page.ptrs[i%n], maxCB + uintptr(i)
+}
+
+// allocClosures maps a page of trampolines for new closures. The trampolines are
+// written once for all the indices they cover and the page is never written again,
+// so that a trampoline can be reused without modifying executable memory.
+// closures.lock must be held.
+func allocClosures() {
+	size := syscall.Getpagesize()
+	mem, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		panic("purego: cannot allocate memory for closures: " + err.Error())
+	}
+	var pages []*closurePage
+	if p := closures.pages.Load(); p != nil {
+		pages = *p
+	}
+	n := closuresPerPage()
+	base := len(pages) * n
+	for j := range n {
+		writeClosureTrampoline(mem[j*closureTrampolineSize:(j+1)*closureTrampolineSize], maxCB+uintptr(base+j))
+	}
+	if err := mprotect(mem, syscall.PROT_READ|syscall.PROT_EXEC); err != nil {
+		panic("purego: cannot make closures executable: " + err.Error())
+	}
+	flushInstructionCache(uintptr(unsafe.Pointer(&mem[0])), uintptr(size))
+	page := &closurePage{
+		callbacks: make([]atomic.Pointer[callback], n),
+		ptrs:      make([]uintptr, n),
+	}
+	for j := range n {
+		page.ptrs[j] = uintptr(unsafe.Pointer(&mem[j*closureTrampolineSize]))
+	}
+	// Readers may still be using the old slice, so publish a new one.
+	pages = append(pages[:len(pages):len(pages)], page)
+	closures.pages.Store(&pages)
+	// Hand out the lowest index first.
+	for j := n - 1; j >= 0; j-- {
+		closures.free = append(closures.free, base+j)
+	}
+}
+
+// mprotect calls the C function mprotect since the syscall package does not provide it on every platform.
+func mprotect(b []byte, prot int) error {
+	fn, err := Dlsym(RTLD_DEFAULT, "mprotect")
+	if err != nil {
+		return err
+	}
+	if err := errnoSupported(); err != nil {
+		return err
+	}
+	var args [maxArgs]uintptr
+	args[0] = uintptr(unsafe.Pointer(&b[0]))
+	args[1] = uintptr(len(b))
+	args[2] = uintptr(prot)
+	loc := beginErrno()
+	s := syscall_SyscallN(fn, args[:], args[:], 0)
+	r := int32(s.a1)
+	errno := endErrno(loc, s)
+	thePool.Put(s)
+	if r != 0 {
+		return syscall.Errno(errno)
+	}
+	return nil
+}
+
+// closureSlot returns where the callback of the closure with the callback index is stored,
+// or nil if there is no such closure.
+func closureSlot(index uintptr) *atomic.Pointer[callback] {
+	p := closures.pages.Load()
+	if p == nil {
+		return nil
+	}
+	i := index - maxCB
+	n := uintptr(closuresPerPage())
+	if i/n >= uintptr(len(*p)) {
+		return nil
+	}
+	return &(*p)[i/n].callbacks[i%n]
+}
+
+// lookupClosure returns the callback of the closure with the callback index.
+// It returns nil if the closure was closed.
+func lookupClosure(index uintptr) *callback {
+	slot := closureSlot(index)
+	if slot == nil {
+		panic("purego: invalid closure index")
+	}
+	return slot.Load()
+}
+
+func releaseClosure(index uintptr) {
+	closures.lock.Lock()
+	defer closures.lock.Unlock()
+	slot := closureSlot(index)
+	if slot == nil || slot.Load() == nil {
+		panic("purego: Close called with a closure that is not in use")
+	}
+	slot.Store(nil)
+	closures.free = append(closures.free, int(index-maxCB))
+}
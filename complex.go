@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// complex64Parts and complex128Parts are laid out and passed like float _Complex and
+// double _Complex, which C treats as a struct of the real part and the imaginary part.
+type complex64Parts struct {
+	Real, Imag float32
+}
+
+type complex128Parts struct {
+	Real, Imag float64
+}
+
+// cLayoutTypes caches the result of cLayoutType for struct and array types.
+var cLayoutTypes sync.Map // map[reflect.Type]reflect.Type
+
+// cLayoutType returns t with every complex number replaced by the struct of its parts, so that the
+// struct rules of the calling convention place it. The result has the same size and field offsets
+// as t. t is returned as is when it does not contain a complex number.
+func cLayoutType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Complex64:
+		return reflect.TypeFor[complex64Parts]()
+	case reflect.Complex128:
+		return reflect.TypeFor[complex128Parts]()
+	case reflect.Struct, reflect.Array:
+		if isUnionType(t) {
+			// The members of a union are classified as they are, see homogeneousFloats.
+			return t
+		}
+	default:
+		return t
+	}
+	if l, ok := cLayoutTypes.Load(t); ok {
+		return l.(reflect.Type)
+	}
+	l := t
+	switch t.Kind() {
+	case reflect.Array:
+		if elem := cLayoutType(t.Elem()); elem != t.Elem() {
+			l = reflect.ArrayOf(t.Len(), elem)
+		}
+	case reflect.Struct:
+		fields := make([]reflect.StructField, t.NumField())
+		changed := false
+		for i := range fields {
+			f := t.Field(i)
+			// Name the fields anew since reflect.StructOf does not accept unexported fields.
+			fields[i] = reflect.StructField{Name: "F" + strconv.Itoa(i), Type: cLayoutType(f.Type)}
+			changed = changed || fields[i].Type != f.Type
+		}
+		if changed {
+			l = reflect.StructOf(fields)
+		}
+	}
+	actual, _ := cLayoutTypes.LoadOrStore(t, l)
+	return actual.(reflect.Type)
+}
+
+// toCLayout returns v as a value of cLayoutType(v.Type()) that shares its memory.
+func toCLayout(v reflect.Value) reflect.Value {
+	return convertLayout(cLayoutType(v.Type()), v)
+}
+
+// fromCLayout returns v, a value of cLayoutType(t), as a value of type t that shares its memory.
+func fromCLayout(t reflect.Type, v reflect.Value) reflect.Value {
+	return convertLayout(t, v)
+}
+
+func convertLayout(t reflect.Type, v reflect.Value) reflect.Value {
+	if v.Type() == t {
+		return v
+	}
+	if !v.CanAddr() {
+		tmp := reflect.New(v.Type()).Elem()
+		tmp.Set(v)
+		v = tmp
+	}
+	return reflect.NewAt(t, v.Addr().UnsafePointer()).Elem()
+}
+
+// isComplexKind reports whether k is a complex number kind.
+func isComplexKind(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+// ensureComplexSupported panics if complex numbers cannot be passed to or returned from C
+// on the current platform.
+func ensureComplexSupported() {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		panic("purego: complex numbers are only supported on amd64 and arm64")
+	}
+}
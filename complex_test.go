@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || linux || windows) && (amd64 || arm64)
+
+package purego_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+func openComplexTest(t *testing.T) uintptr {
+	t.Helper()
+	libFileName := filepath.Join(t.TempDir(), "complextest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "complextest", "complex_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+type signal struct {
+	ID    int32
+	Gain  complex64
+	Phase complex128
+}
+
+func TestComplex(t *testing.T) {
+	lib := openComplexTest(t)
+
+	var multiply128 func(a, b complex128) complex128
+	purego.RegisterLibFunc(&multiply128, lib, "multiply128")
+	if got, want := multiply128(1+2i, 3-1i), complex128(5+5i); got != want {
+		t.Errorf("multiply128() = %v, want %v", got, want)
+	}
+
+	var multiply64 func(a, b complex64) complex64
+	purego.RegisterLibFunc(&multiply64, lib, "multiply64")
+	if got, want := multiply64(1+2i, 3-1i), complex64(5+5i); got != want {
+		t.Errorf("multiply64() = %v, want %v", got, want)
+	}
+
+	var multiplyAny func(args ...any) complex128
+	purego.RegisterLibFunc(&multiplyAny, lib, "multiply128")
+	if got, want := multiplyAny(complex128(1+2i), complex128(3-1i)), complex128(5+5i); got != want {
+		t.Errorf("multiply128() with ...any = %v, want %v", got, want)
+	}
+
+	var norm128 func(a complex128) float64
+	purego.RegisterLibFunc(&norm128, lib, "norm128")
+	if got := norm128(3 + 4i); got != 25 {
+		t.Errorf("norm128() = %v, want 25", got)
+	}
+
+	var mixed func(a int32, b complex64, c float64, d complex128, e int64) complex128
+	purego.RegisterLibFunc(&mixed, lib, "mixed")
+	if got, want := mixed(1, 2+3i, 4, 5+6i, 7), complex128(19+9i); got != want {
+		t.Errorf("mixed() = %v, want %v", got, want)
+	}
+
+	var sum5 func(a, b, c, d, e complex128) complex128
+	purego.RegisterLibFunc(&sum5, lib, "sum5")
+	if got, want := sum5(1+1i, 2+2i, 3+3i, 4+4i, 5+5i), complex128(15+15i); got != want {
+		t.Errorf("sum5() = %v, want %v", got, want)
+	}
+
+	var signalSum func(s signal) complex128
+	purego.RegisterLibFunc(&signalSum, lib, "signalSum")
+	if got, want := signalSum(signal{ID: 1, Gain: 2 + 3i, Phase: 4 + 5i}), complex128(7+8i); got != want {
+		t.Errorf("signalSum() = %v, want %v", got, want)
+	}
+
+	type sample struct {
+		Value  complex64
+		Weight float32
+	}
+	var makeSample func(value complex64, weight float32) sample
+	purego.RegisterLibFunc(&makeSample, lib, "makeSample")
+	if got, want := makeSample(1+2i, 3), (sample{1 + 2i, 3}); got != want {
+		t.Errorf("makeSample() = %v, want %v", got, want)
+	}
+}
+
+func TestComplexCallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("complex numbers in callbacks are not supported on Windows")
+	}
+	lib := openComplexTest(t)
+
+	var callComplex func(cb func(a complex128, b complex64) complex128, a complex128, b complex64) complex128
+	purego.RegisterLibFunc(&callComplex, lib, "callComplex")
+	got := callComplex(func(a complex128, b complex64) complex128 {
+		return a * complex128(b)
+	}, 1+2i, 3-1i)
+	if want := complex128(5 + 5i); got != want {
+		t.Errorf("callComplex() = %v, want %v", got, want)
+	}
+
+	var callSignal func(cb func(s signal) signal, s signal) signal
+	purego.RegisterLibFunc(&callSignal, lib, "callSignal")
+	gotSignal := callSignal(func(s signal) signal {
+		s.ID++
+		s.Gain *= 2
+		s.Phase *= 1i
+		return s
+	}, signal{ID: 1, Gain: 2 + 3i, Phase: 4 + 5i})
+	if want := (signal{ID: 2, Gain: 4 + 6i, Phase: -5 + 4i}); gotSignal != want {
+		t.Errorf("callSignal() = %v, want %v", gotSignal, want)
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// debug holds the settings read from the PUREGO_DEBUG environment variable.
+// Like GODEBUG it is a comma-separated list of name=value pairs.
+var debug struct {
+	// layoutcheck makes RegisterFunc check every struct argument and return type with CheckLayout.
+	layoutcheck bool
+	// cgocheck makes functions registered with RegisterFunc check their pointer arguments like GODEBUG=cgocheck=1.
+	cgocheck bool
+}
+
+func init() {
+	env, _ := syscall.Getenv("PUREGO_DEBUG")
+	for _, setting := range strings.Split(env, ",") {
+		name, value, _ := strings.Cut(setting, "=")
+		switch name {
+		case "layoutcheck":
+			debug.layoutcheck = value == "1"
+		case "cgocheck":
+			debug.cgocheck = value == "1"
+		}
+	}
+}
+
+// cgoCheckArg panics if the argument v with index i is a Go pointer to unpinned Go memory that contains
+// Go pointers, or a slice or struct holding one, which cgo does not allow to be passed to C.
+// The check is done by the runtime, so it is disabled by GODEBUG=cgocheck=0.
+func cgoCheckArg(i int, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Slice, reflect.Struct:
+	default:
+		return
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String ||
+		isNullStringType(v.Type()) || isStringPointerType(v.Type()) {
+		// These are not passed to C themselves. Each string is copied into a NUL-terminated
+		// Go buffer that holds no pointers, and the array of those buffers made for a []string
+		// is pinned together with them until the call returns.
+		return
+	}
+	if isScopedCallbackType(v.Type()) {
+		// The function is passed as a callback.
+		return
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, ok := r.(runtime.Error)
+		if !ok {
+			panic(r)
+		}
+		// The runtime reports the problem as an argument of a cgo function.
+		msg := strings.TrimPrefix(err.Error(), "runtime error: argument of cgo function ")
+		panic("purego: argument " + strconv.Itoa(i) + " of type " + v.Type().String() + " " + msg)
+	}()
+	if isPinnedType(v.Type()) {
+		// The pointer of a Pinned is pinned but the memory it points to is checked like any other.
+		runtime_cgoCheckPointer(v.Field(0).UnsafePointer(), nil)
+		return
+	}
+	runtime_cgoCheckPointer(v.Interface(), nil)
+}
+
+// cgoCheckArgs calls cgoCheckArg for every argument of a function created by the NewFuncN functions.
+// The arguments are copied into interfaces so that checking them does not make them escape.
+func cgoCheckArgs(args ...any) {
+	for i, x := range args {
+		cgoCheckArg(i, reflect.ValueOf(x))
+	}
+}
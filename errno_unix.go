@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd
+
+package purego
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+var errnoLocation struct {
+	once sync.Once
+	fn   uintptr // the libc function that returns the address of errno for the calling thread
+	err  error
+}
+
+// errnoSupported returns an error if errno cannot be read on this platform.
+func errnoSupported() error {
+	errnoLocation.once.Do(func() {
+		var name string
+		switch runtime.GOOS {
+		case "darwin", "ios", "freebsd":
+			name = "__error"
+		case "android", "netbsd":
+			name = "__errno"
+		default:
+			name = "__errno_location"
+		}
+		fn, err := Dlsym(RTLD_DEFAULT, name)
+		if err != nil {
+			errnoLocation.err = errors.New("purego: cannot read errno: " + err.Error())
+			return
+		}
+		errnoLocation.fn = fn
+	})
+	return errnoLocation.err
+}
+
+// beginErrno locks the calling goroutine to its current OS thread and sets errno to zero.
+// The returned address of errno must be passed to endErrno right after the C function returns.
+func beginErrno() unsafe.Pointer {
+	runtime.LockOSThread()
+	var args [maxArgs]uintptr
+	s := syscall_SyscallN(errnoLocation.fn, args[:], args[:], 0)
+	// errno lives in C memory so this conversion is safe.
+	loc := *(*unsafe.Pointer)(unsafe.Pointer(&s.a1))
+	thePool.Put(s)
+	*(*int32)(loc) = 0
+	return loc
+}
+
+// endErrno returns the errno set by the C function called after beginErrno
+// and unlocks the goroutine from its OS thread.
+func endErrno(loc unsafe.Pointer, _ *syscallArgs) uintptr {
+	errno := *(*int32)(loc)
+	runtime.UnlockOSThread()
+	return uintptr(errno)
+}
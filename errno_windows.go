@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+package purego
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procGetLastError = syscall.NewLazyDLL("kernel32.dll").NewProc("GetLastError")
+	procSetLastError = syscall.NewLazyDLL("kernel32.dll").NewProc("SetLastError")
+)
+
+// errnoSupported returns an error if errno cannot be read on this platform.
+// On Windows the error code is the one reported by GetLastError.
+func errnoSupported() error {
+	if runtime.GOARCH != "arm64" {
+		// syscall.SyscallN already reports GetLastError.
+		return nil
+	}
+	if err := procGetLastError.Find(); err != nil {
+		return errors.New("purego: cannot read errno: " + err.Error())
+	}
+	if err := procSetLastError.Find(); err != nil {
+		return errors.New("purego: cannot read errno: " + err.Error())
+	}
+	return nil
+}
+
+// beginErrno prepares reading the error code of the next C function call.
+// On arm64 it locks the calling goroutine to its current OS thread and clears the last error.
+func beginErrno() unsafe.Pointer {
+	if runtime.GOARCH != "arm64" {
+		return nil
+	}
+	runtime.LockOSThread()
+	var args [maxArgs]uintptr
+	thePool.Put(syscall_SyscallN(procSetLastError.Addr(), args[:], args[:], 0))
+	return nil
+}
+
+// endErrno returns the error code set by the C function called after beginErrno.
+func endErrno(_ unsafe.Pointer, s *syscallArgs) uintptr {
+	if runtime.GOARCH != "arm64" {
+		// a3 holds the error returned by syscall.SyscallN.
+		return s.a3
+	}
+	var args [maxArgs]uintptr
+	r := syscall_SyscallN(procGetLastError.Addr(), args[:], args[:], 0)
+	errno := r.a1
+	thePool.Put(r)
+	runtime.UnlockOSThread()
+	return errno
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrTooManyArguments is returned by [TryRegisterFunc] when the arguments of a function
+// do not fit into the registers and stack space that purego passes to a C function.
+var ErrTooManyArguments = errors.New("purego: too many stack arguments")
+
+// UnsupportedTypeError is returned by [TryRegisterFunc] when an argument or a return value
+// has a type that cannot be passed to or returned from a C function.
+type UnsupportedTypeError struct {
+	// Arg is the index of the argument with the unsupported type, or -1 if it is a return value.
+	Arg int
+	// Type is the unsupported type.
+	Type reflect.Type
+
+	msg string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return e.msg
+}
+
+// SymbolNotFoundError is returned by [TryRegisterLibFunc] when the symbol cannot be found in the library.
+type SymbolNotFoundError struct {
+	// Name is the name of the symbol.
+	Name string
+	// Err is the error reported while looking up the symbol.
+	Err error
+}
+
+func (e *SymbolNotFoundError) Error() string {
+	return "purego: symbol " + e.Name + " not found: " + e.Err.Error()
+}
+
+func (e *SymbolNotFoundError) Unwrap() error {
+	return e.Err
+}
@@ -5,7 +5,10 @@
 
 package purego
 
-import "reflect"
+import (
+	"reflect"
+	"unsafe"
+)
 
 // MaxArgs re-exports maxArgs for external tests.
 const MaxArgs = maxArgs
@@ -14,3 +17,27 @@ const MaxArgs = maxArgs
 func StructReturnInMemory(outType reflect.Type) bool {
 	return structReturnInMemory(outType)
 }
+
+// SetDebugCgocheck sets the cgocheck setting of PUREGO_DEBUG and returns the previous one.
+func SetDebugCgocheck(enabled bool) bool {
+	old := debug.cgocheck
+	debug.cgocheck = enabled
+	return old
+}
+
+// CheckCStringArray passes the array of C strings made for the []string s through the cgo pointer
+// check that cgo applies to an unsafe.Pointer argument of a C function.
+func CheckCStringArray(s []string) {
+	ptr, pinner := cStringArray(reflect.ValueOf(s))
+	if pinner != nil {
+		defer pinner.Unpin()
+	}
+	runtime_cgoCheckPointer(unsafe.Pointer(ptr), nil)
+}
+
+// NaturalLayout returns the layout that CheckLayout uses for the Go struct t when it is given no C layout,
+// and the size of that C struct.
+func NaturalLayout(t reflect.Type) ([]FieldLayout, uintptr, error) {
+	fields, size, _, err := cStructLayout(t)
+	return fields, size, err
+}
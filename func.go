@@ -6,11 +6,13 @@
 package purego
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"runtime"
 	"sync"
+	"syscall"
 	"unsafe"
 
 	"github.com/ebitengine/purego/internal/strings"
@@ -32,14 +34,39 @@ func RegisterLibFunc(fptr any, handle uintptr, name string) {
 	if err != nil {
 		panic(err)
 	}
-	RegisterFunc(fptr, sym)
+	if err := registerFunc(fptr, sym, -1, freeFuncFor(handle)); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegisterLibFunc is like [RegisterLibFunc] but returns an error instead of panicking.
+// A [*SymbolNotFoundError] is returned if it can't find the name symbol.
+func TryRegisterLibFunc(fptr any, handle uintptr, name string) error {
+	sym, err := loadSymbol(handle, name)
+	if err != nil {
+		return &SymbolNotFoundError{Name: name, Err: err}
+	}
+	return registerFunc(fptr, sym, -1, freeFuncFor(handle))
+}
+
+// TryRegisterFunc is like [RegisterFunc] but returns an error instead of panicking when fptr
+// cannot be used to call cfn. A [*UnsupportedTypeError] is returned for an argument or return value
+// whose type is not supported, and [ErrTooManyArguments] when the arguments don't fit into
+// the registers and stack. fptr is left unchanged when an error is returned.
+func TryRegisterFunc(fptr any, cfn uintptr) error {
+	return registerFunc(fptr, cfn, -1, 0)
 }
 
 // RegisterFunc takes a pointer to a Go function representing the calling convention of the C function.
 // fptr will be set to a function that when called will call the C function given by cfn with the
 // parameters passed in the correct registers and stack.
 //
-// A panic is produced if the type is not a function pointer or if the function returns more than 1 value.
+// A panic is produced if the type is not a function pointer or if the function returns more than 1 value,
+// except for the errno described below. Use [TryRegisterFunc] to get an error instead of a panic.
+//
+// Where the arguments go is worked out once per function type, but every call still allocates: fptr is set to a
+// function made by [reflect.MakeFunc], which boxes each argument and the result, so a call with n scalar arguments
+// makes about n+2 allocations. Use [NewFunc1] and the like for calls that do not allocate.
 //
 // These conversions describe how a Go type in the fptr will be used to call
 // the C function. It is important to note that there is no way to verify that fptr
@@ -49,6 +76,8 @@ func RegisterLibFunc(fptr any, handle uintptr, name string) {
 // # Type Conversions (Go <=> C)
 //
 //	string <=> char*
+//	*string, NullString <=> char* (may be NULL)
+//	OwnedString <= char* (freed after it is copied)
 //	bool <=> _Bool
 //	uintptr <=> uintptr_t
 //	uint <=> uint32_t or uint64_t
@@ -63,15 +92,35 @@ func RegisterLibFunc(fptr any, handle uintptr, name string) {
 //	int64 <=> int64_t
 //	float32 <=> float
 //	float64 <=> double
+//	complex64 <=> float _Complex (amd64 and arm64)
+//	complex128 <=> double _Complex (amd64 and arm64)
 //	struct <=> struct (android, darwin, ios, linux, and windows on amd64/arm64)
+//	Union[T1, T2] <=> union (amd64 and arm64)
+//	LongDouble <=> long double (amd64, arm64, and riscv64 except on darwin/arm64 and windows)
+//	Int128, Uint128 <=> __int128, unsigned __int128 (amd64, arm64, and riscv64 except on windows)
+//	Vec128[T] <=> __m128, __m128d, __m128i, float32x4_t and other 128-bit vectors (amd64 and arm64 except on windows/amd64)
 //	func <=> C function
+//	ScopedCallback[F] => C function (only valid for the call)
 //	unsafe.Pointer, *T <=> void*
 //	[]T => void*
+//	[]string <=> char** (NULL-terminated)
+//	Pinned[T] => T*
 //
 // There is a special case when the last argument of fptr is a variadic interface (or []interface}
 // it will be expanded into a call to the C function as if it had the arguments in that slice.
 // This means that using arg ...any is like a cast to the function with the arguments inside arg.
-// This is not the same as C variadic.
+// This is not the same as C variadic. Use [RegisterVariadicFunc] to call a C variadic function.
+//
+// # Errno
+//
+// A function may return a second value of type [syscall.Errno] or error, for example
+//
+//	var open func(path string, flags int32) (int32, syscall.Errno)
+//
+// The calling goroutine is locked to its OS thread for the duration of the call, errno is set to zero before the
+// C function is called and read on the same thread right after it returns. An error result is nil when errno is zero
+// and otherwise holds a [syscall.Errno]. As in C, errno is only meaningful when the result of the function reports
+// a failure. On Windows the error code is the one reported by GetLastError.
 //
 // # Memory
 //
@@ -87,16 +136,42 @@ func RegisterLibFunc(fptr any, handle uintptr, name string) {
 // This can be done using runtime.KeepAlive or allocating the string in C memory using malloc. When a C function
 // returns a null-terminated pointer to char a Go string can be used. Purego will allocate a new string in Go memory
 // and copy the data over. This string will be garbage collected whenever Go decides it's no longer referenced.
-// This C created string will not be freed by purego. If the pointer to char is not null-terminated or must continue
+// This C created string will not be freed by purego unless the result is an [OwnedString]. If the pointer to char is not null-terminated or must continue
 // to point to C memory (because it's a buffer for example) then use a pointer to byte and then convert that to a slice
 // using unsafe.Slice. Doing this means that it becomes the responsibility of the caller to care about the lifetime
 // of the pointer
 //
+// A []string argument is passed as a NULL-terminated array of strings that are copied like a string argument, so
+// both the array and the strings are only valid for that specific call. A nil []string is passed as NULL.
+// A returned char** is read up to the first NULL and its strings are copied into Go memory. The C array is not freed.
+// Use [RegisterStringArrayFunc] for an array that is paired with a count or that must be freed.
+//
+// A C string that may be NULL is passed as a *string or a [NullString], where nil or an invalid NullString is
+// passed as NULL. Likewise, a char* result received as a *string or a NullString tells NULL apart from an empty
+// string.
+//
+// A C function that keeps a Go pointer after it returns, such as the buffer of an asynchronous read, must receive it
+// as a [Pinned] argument created by [Pin]. The memory then stays pinned until [Pinned.Unpin] is called.
+//
+// A func argument is converted to a C function pointer with [NewCallback] the first time the function value is passed.
+// The callback is kept for the lifetime of the process since the C function may hold onto it, such as a handler that
+// it registers, and passing the same function value again, such as the same top-level function, reuses it. A closure
+// that is created anew for every call is a different function value each time and uses up another callback. Pass it
+// as a [ScopedCallback] instead when the C function does not keep the function pointer after it returns, such as the
+// comparison function of qsort. Its callback is then released when the call returns.
+//
+// Setting PUREGO_DEBUG=cgocheck=1 in the environment makes the registered function check its pointer, slice and
+// struct arguments like GODEBUG=cgocheck=1 does for cgo. It panics when an argument is a Go pointer to unpinned Go
+// memory that contains Go pointers.
+//
 // # Structs
 //
 // Purego can handle the most common structs that have fields of builtin types like int8, uint16, float32, etc. However,
 // it does not support aligning fields properly. It is therefore the responsibility of the caller to ensure
 // that all padding is added to the Go struct to match the C one. See `BoolStructFn` in struct_test.go for an example.
+// [CheckLayout] reports the fields of a Go struct that are not placed like those of the C struct.
+// A C union, whether passed by value or as a field of a struct, is declared with [Union] so that
+// purego can place it according to all of its members.
 //
 // On Apple ARM64 platforms (macOS and iOS), purego handles proper alignment of struct arguments
 // when passing them on the stack, following the C ABI's byte-level packing rules.
@@ -122,118 +197,171 @@ func RegisterLibFunc(fptr any, handle uintptr, name string) {
 //
 // [Cgo rules]: https://pkg.go.dev/cmd/cgo#hdr-Go_references_to_C
 func RegisterFunc(fptr any, cfn uintptr) {
-	const is32bit = unsafe.Sizeof(uintptr(0)) == 4
-	fn := reflect.ValueOf(fptr).Elem()
-	ty := fn.Type()
-	if ty.Kind() != reflect.Func {
+	if err := registerFunc(fptr, cfn, -1, 0); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterVariadicFunc is like [RegisterFunc] but calls a C variadic function such as printf or ioctl.
+// The first fixedArgs arguments of fptr are the named parameters of the C function. The remaining
+// arguments, including every element of a trailing ...any argument, are passed as the variadic arguments.
+//
+// The variadic arguments follow the C default argument promotions, so a float32 is passed as a double,
+// and the variadic calling convention of the platform. For example, on Darwin ARM64 every variadic
+// argument is passed on the stack, on amd64 the number of vector registers is passed in AL, and on
+// 32-bit ARM every floating-point argument, including the fixed ones, is passed in the core registers.
+// Structs cannot be passed as variadic arguments.
+//
+//	var snprintf func(buf []byte, size uintptr, format string, args ...any) int32
+//	purego.RegisterVariadicFunc(&snprintf, cfn, 3)
+//	snprintf(buf, uintptr(len(buf)), "%s %.2f", "pi", math.Pi)
+func RegisterVariadicFunc(fptr any, cfn uintptr, fixedArgs int) {
+	if fixedArgs < 0 {
+		panic("purego: fixedArgs must not be negative")
+	}
+	if err := registerFunc(fptr, cfn, fixedArgs, 0); err != nil {
+		panic(err)
+	}
+}
+
+// StringArrayOptions describes how [RegisterStringArrayFunc] reads the char** returned by a C function.
+type StringArrayOptions struct {
+	// CountArg is the index of the argument giving the number of strings in the array. It is either an integer,
+	// such as the size of backtrace_symbols, or a pointer to an integer that the C function sets, such as an
+	// int *count out-parameter. A negative CountArg means that the array is terminated by NULL.
+	CountArg int
+	// Free is called with the array after its strings are copied into Go memory, unless the array is NULL.
+	// It may free the strings too, as g_strfreev does. The array is not freed if Free is nil.
+	Free func(array uintptr)
+}
+
+// RegisterStringArrayFunc is like [RegisterFunc] for a C function that returns an array of strings as a char**.
+// The first result of fptr must be a []string, which receives copies of the strings, or nil when the C function
+// returns NULL. opts gives where the number of strings comes from and how the array is freed.
+//
+//	var backtraceSymbols func(buffer *uintptr, size int32) []string
+//	purego.RegisterStringArrayFunc(&backtraceSymbols, cfn, purego.StringArrayOptions{CountArg: 1, Free: free})
+func RegisterStringArrayFunc(fptr any, cfn uintptr, opts StringArrayOptions) {
+	ptr := reflect.ValueOf(fptr)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Func {
 		panic("purego: fptr must be a function pointer")
 	}
-	if ty.NumOut() > 1 {
-		panic("purego: function can only return zero or one values")
+	ty := ptr.Elem().Type()
+	if ty.NumOut() == 0 || ty.Out(0).Kind() != reflect.Slice || ty.Out(0).Elem().Kind() != reflect.String {
+		panic("purego: the first result of RegisterStringArrayFunc must be a []string")
 	}
-	if cfn == 0 {
-		panic("purego: cfn is nil")
+	if opts.CountArg >= ty.NumIn() {
+		panic("purego: CountArg is larger than the number of arguments")
 	}
-	if ty.NumOut() == 1 && (ty.Out(0).Kind() == reflect.Float32 || ty.Out(0).Kind() == reflect.Float64) &&
-		runtime.GOARCH != "arm" && runtime.GOARCH != "arm64" && runtime.GOARCH != "386" && runtime.GOARCH != "amd64" && runtime.GOARCH != "loong64" && runtime.GOARCH != "ppc64le" && runtime.GOARCH != "riscv64" && runtime.GOARCH != "s390x" {
-		panic("purego: float returns are not supported")
+	if opts.CountArg >= 0 {
+		count := ty.In(opts.CountArg)
+		if count.Kind() == reflect.Pointer {
+			count = count.Elem()
+		}
+		if !isIntegerKind(count.Kind()) {
+			panic("purego: the count argument must be an integer or a pointer to an integer")
+		}
 	}
-	{
-		// this code checks how many registers and stack this function will use
-		// to avoid crashing with too many arguments
-		var ints int
-		var floats int
-		floatArgRegs := numOfFloatRegisters()
-		var stack int
-		for i := 0; i < ty.NumIn(); i++ {
-			arg := ty.In(i)
-			switch arg.Kind() {
-			case reflect.Func:
-				// This only does preliminary testing to ensure the CDecl argument
-				// is the first argument. Full testing is done when the callback is actually
-				// created in NewCallback.
-				for j := 0; j < arg.NumIn(); j++ {
-					in := arg.In(j)
-					if !in.AssignableTo(reflect.TypeFor[CDecl]()) {
-						continue
-					}
-					if j != 0 {
-						panic("purego: CDecl must be the first argument")
-					}
-				}
-			case reflect.String, reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Pointer, reflect.UnsafePointer,
-				reflect.Slice, reflect.Bool:
-				if ints < numOfIntegerRegisters() {
-					ints++
-				} else {
-					stack++
-				}
-			case reflect.Float32, reflect.Float64:
-				if floats < floatArgRegs {
-					floats++
-				} else {
-					stack++
-				}
-			case reflect.Struct:
-				ensureStructSupported()
-				if arg.Size() == 0 && runtime.GOOS != "windows" {
-					// On Windows an empty struct still consumes one argument slot.
-					continue
-				}
-				addInt := func(u uintptr) {
-					ints++
-				}
-				addFloat := func(u uintptr) {
-					floats++
-				}
-				addStack := func(u uintptr) {
-					stack++
+
+	// The C function is called through a function that returns the array as a uintptr.
+	outs := []reflect.Type{reflect.TypeFor[uintptr]()}
+	if ty.NumOut() > 1 {
+		outs = append(outs, ty.Out(1))
+	}
+	cfunc := reflect.New(reflect.FuncOf(funcIn(ty), outs, ty.IsVariadic()))
+	RegisterFunc(cfunc.Interface(), cfn)
+	call := cfunc.Elem().Call
+	if ty.IsVariadic() {
+		call = cfunc.Elem().CallSlice
+	}
+	outType := ty.Out(0)
+	ptr.Elem().Set(reflect.MakeFunc(ty, func(args []reflect.Value) []reflect.Value {
+		results := call(args)
+		array := uintptr(results[0].Uint())
+		n := -1
+		if opts.CountArg >= 0 {
+			count := args[opts.CountArg]
+			if count.Kind() == reflect.Pointer {
+				if count.IsNil() {
+					panic("purego: the count argument is nil")
 				}
-				_ = addStruct(reflect.New(arg).Elem(), &ints, &floats, &stack, addInt, addFloat, addStack, nil)
-			default:
-				panic("purego: unsupported kind " + arg.Kind().String())
+				count = count.Elem()
 			}
+			n = int(integerValue(count))
 		}
-		if ty.NumOut() == 1 && ty.Out(0).Kind() == reflect.Struct {
-			ensureStructSupported()
-			outType := ty.Out(0)
-			checkStructFieldsSupported(outType)
-			if structReturnInMemory(outType) {
-				// A struct returned in memory is allocated by the caller and its
-				// pointer is passed as a hidden first integer argument. When the
-				// integer registers are already full, prepending it spills a
-				// regular argument onto the stack.
-				if ints < numOfIntegerRegisters() {
-					ints++
-				} else {
-					stack++
-				}
-			}
+		strs := reflect.New(outType).Elem()
+		*(*[]string)(strs.Addr().UnsafePointer()) = goStrings(array, n)
+		if array != 0 && opts.Free != nil {
+			opts.Free(array)
 		}
+		results[0] = strs
+		return results
+	}))
+}
 
-		argsLimit := maxArgs
-		sizeOfStack := argsLimit - numOfIntegerRegisters()
-		if runtime.GOOS == "windows" {
-			if ints+floats+stack > argsLimit {
-				panic("purego: too many stack arguments")
-			}
-		} else if isDarwin && runtime.GOARCH == "arm64" {
-			// On Darwin ARM64, use byte-based validation since arguments pack efficiently.
-			// See https://developer.apple.com/documentation/xcode/writing-arm64-code-for-apple-platforms
-			stackBytes := estimateStackBytes(ty)
-			maxStackBytes := sizeOfStack * 8
-			if stackBytes > maxStackBytes {
-				panic("purego: too many stack arguments")
-			}
-		} else {
-			if stack > sizeOfStack {
-				panic("purego: too many stack arguments")
-			}
+// isIntegerKind reports whether k is a signed or unsigned integer kind.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// integerValue returns the value of the integer v as an int64.
+func integerValue(v reflect.Value) int64 {
+	if v.CanInt() {
+		return v.Int()
+	}
+	return int64(v.Uint())
+}
+
+// registerFunc sets fptr to a function that calls cfn. If fixedArgs is not negative,
+// the arguments after the first fixedArgs are passed as C variadic arguments.
+// free is the C function that frees an OwnedString result, or 0 if there is none.
+func registerFunc(fptr any, cfn uintptr, fixedArgs int, free uintptr) error {
+	ptr := reflect.ValueOf(fptr)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Func {
+		return errors.New("purego: fptr must be a function pointer")
+	}
+	fn := ptr.Elem()
+	ty := fn.Type()
+	if cfn == 0 {
+		return errors.New("purego: cfn is nil")
+	}
+	if err := checkFuncType(ty); err != nil {
+		return err
+	}
+	if ty.NumOut() > 0 && isOwnedStringType(ty.Out(0)) && free == 0 {
+		return &UnsupportedTypeError{Arg: -1, Type: ty.Out(0), msg: "purego: OwnedString can only be returned by a library function whose deallocator is set by SetFreeFunc"}
+	}
+	if fixedArgs >= 0 {
+		named := ty.NumIn()
+		if ty.IsVariadic() && ty.In(named-1) == reflect.TypeFor[[]any]() {
+			named--
+		}
+		if fixedArgs > named {
+			return errors.New("purego: fixedArgs is larger than the number of arguments")
 		}
 	}
 
+	plan := planFor(ty)
 	v := reflect.MakeFunc(ty, func(args []reflect.Value) (results []reflect.Value) {
+		if debug.cgocheck {
+			for i, v := range args {
+				if variadic, ok := reflect.TypeAssert[[]any](v); ok {
+					for _, x := range variadic {
+						cgoCheckArg(i, reflect.ValueOf(x))
+					}
+					continue
+				}
+				cgoCheckArg(i, v)
+			}
+		}
+		if plan.fixed && fixedArgs < 0 {
+			return plan.call(cfn, free, args)
+		}
 		var sysargs [maxArgs]uintptr
 		// Use maxArgs instead of numOfFloatRegisters() to keep this code path allocation-free,
 		// since numOfFloatRegisters() is a function call, not a constant.
@@ -244,6 +372,7 @@ func RegisterFunc(fptr any, cfn uintptr) {
 		var numFloats int
 		var numStack int
 		var addStack, addInt, addFloat func(x uintptr)
+		var addQuad func(lo, hi uintptr)
 		if runtime.GOARCH == "arm64" || runtime.GOOS != "windows" {
 			// Windows arm64 uses the same calling convention as macOS and Linux
 			addStack = func(x uintptr) {
@@ -266,6 +395,12 @@ func RegisterFunc(fptr any, cfn uintptr) {
 					addStack(x)
 				}
 			}
+			addQuad = func(lo, hi uintptr) {
+				// The upper halves of the vector registers follow the lower halves of all of them.
+				floats[numFloats] = lo
+				floats[floatArgRegs+numFloats] = hi
+				numFloats++
+			}
 		} else {
 			// On Windows amd64 the arguments are passed in the numbered registered.
 			// So the first int is in the first integer register and the first float
@@ -285,13 +420,23 @@ func RegisterFunc(fptr any, cfn uintptr) {
 
 		var keepAlive []any
 		defer func() {
+			for _, x := range keepAlive {
+				// Release the callbacks created for ScopedCallback arguments and unpin the arrays
+				// of C strings now that the C function returned.
+				switch x := x.(type) {
+				case funcCallbackKey:
+					releaseFuncCallback(x)
+				case *runtime.Pinner:
+					x.Unpin()
+				}
+			}
 			runtime.KeepAlive(keepAlive)
 			runtime.KeepAlive(args)
 		}()
 
 		var arm64_r8 uintptr
-		if ty.NumOut() == 1 && ty.Out(0).Kind() == reflect.Struct {
-			outType := ty.Out(0)
+		if ty.NumOut() > 0 && isStructResult(ty.Out(0)) {
+			outType := cLayoutType(ty.Out(0))
 			if structReturnInMemory(outType) {
 				// The caller allocates the return value and passes its pointer
 				// as a hidden first integer argument.
@@ -307,156 +452,1067 @@ func RegisterFunc(fptr any, cfn uintptr) {
 				}
 			}
 		}
+		// fixedEnd is the index of the first argument passed as a C variadic argument.
+		fixedEnd := len(args)
+		if fixedArgs >= 0 {
+			fixedEnd = fixedArgs
+		}
+		if plan.complex {
+			// Pass a complex number as the struct of its parts so that it is placed like any other struct.
+			for i, v := range args {
+				args[i] = toCLayout(v)
+			}
+		}
+		if plan.pointers {
+			// Pass a Pinned as the pointer it holds, and a NullString or *string as a C string,
+			// so that they are placed like any other pointer.
+			for i, v := range args {
+				switch {
+				case isPinnedType(v.Type()):
+					args[i] = reflect.ValueOf(v.Field(0).UnsafePointer())
+				case isNullStringType(v.Type()) || isStringPointerType(v.Type()):
+					args[i] = reflect.ValueOf(nullableCString(v))
+				case isScopedCallbackType(v.Type()):
+					ptr, key := scopedCallback(v)
+					keepAlive = append(keepAlive, key)
+					args[i] = reflect.ValueOf(ptr)
+				}
+			}
+		}
+		var bundled bool
 		for i, v := range args {
+			if bundled && i < fixedEnd {
+				continue
+			}
 			if variadic, ok := reflect.TypeAssert[[]any](args[i]); ok {
 				if i != len(args)-1 {
 					panic("purego: can only expand last parameter")
 				}
 				for _, x := range variadic {
-					keepAlive = addValue(reflect.ValueOf(x), keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
+					if fixedArgs >= 0 {
+						keepAlive = addVariadic(reflect.ValueOf(x), keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
+					} else {
+						keepAlive = addValue(reflect.ValueOf(x), keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
+					}
 				}
 				continue
 			}
+			if i >= fixedEnd {
+				keepAlive = addVariadic(v, keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
+				continue
+			}
+			if fixedArgs >= 0 && runtime.GOOS != "windows" && runtime.GOARCH == "arm" &&
+				(v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {
+				// The fixed floating-point arguments of a variadic function are passed in the core registers too.
+				keepAlive = addValue(v, keepAlive, addInt, alignCoreFloat(v, addInt, addFloat, addStack, &numInts, &numStack),
+					addStack, &numInts, &numFloats, &numStack)
+				continue
+			}
+			if plan.longDouble && addLongDouble(v, addInt, addStack, addQuad, &numInts, &numFloats, &numStack) {
+				continue
+			}
+			if plan.int128 && addInt128(v, addInt, addStack, &numInts, &numStack) {
+				continue
+			}
+			if plan.vector && addVector(v, addStack, addQuad, &numFloats, &numStack) {
+				continue
+			}
 			// Check if we need to start Darwin ARM64 C-style stack packing
 			if runtime.GOARCH == "arm64" && isDarwin && shouldBundleStackArgs(v, numInts, numFloats) {
-				// Collect and separate remaining args into register vs stack
-				stackArgs, newKeepAlive := collectStackArgs(args, i, numInts, numFloats,
-					keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
+				// Collect and separate remaining fixed args into register vs stack
+				stackArgs, newKeepAlive := collectStackArgs(args[:fixedEnd], i, numInts, numFloats,
+					keepAlive, addInt, addFloat, addStack, addQuad, &numInts, &numFloats, &numStack)
 				keepAlive = newKeepAlive
 
 				// Bundle stack arguments with C-style packing
 				bundleStackArgs(stackArgs, addStack)
-				break
+				bundled = true
+				continue
 			}
 			keepAlive = addValue(v, keepAlive, addInt, addFloat, addStack, &numInts, &numFloats, &numStack)
 		}
 
+		var errnoLoc unsafe.Pointer
+		if plan.errno != nil {
+			errnoLoc = beginErrno()
+		}
 		var syscall *syscallArgs
 		if runtime.GOOS == "windows" && runtime.GOARCH != "arm64" {
 			// Windows amd64, 386, and arm use syscall.SyscallN.
 			syscall = thePool.Get().(*syscallArgs)
-			syscall.a1, syscall.a2, _ = syscall_syscallN(cfn, sysargs[:numStack]...)
+			syscall.a1, syscall.a2, syscall.a3 = syscall_syscallN(cfn, sysargs[:numStack]...)
 			syscall.f1 = syscall.a2 // on amd64 a2 stores the float return. On 32bit platforms floats aren't support
 		} else {
 			syscall = syscall_SyscallN(cfn, sysargs[:], floats[:], arm64_r8)
 		}
+		var errno uintptr
+		if plan.errno != nil {
+			errno = endErrno(errnoLoc, syscall)
+		}
 		defer thePool.Put(syscall)
 		if ty.NumOut() == 0 {
 			return nil
 		}
-		outType := ty.Out(0)
-		v := reflect.New(outType).Elem()
-		switch outType.Kind() {
-		case reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			v.SetUint(uint64(syscall.a1))
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			v.SetInt(int64(syscall.a1))
-		case reflect.Bool:
-			v.SetBool(byte(syscall.a1) != 0)
-		case reflect.UnsafePointer:
-			// We take the address and then dereference it to trick go vet from creating a possible miss-use of unsafe.Pointer
-			v.SetPointer(*(*unsafe.Pointer)(unsafe.Pointer(&syscall.a1)))
-		case reflect.Pointer:
-			// Copy syscall.a1 into a local variable to prevent v
-			// from holding a pointer to the pooled syscallArgs field.
-			a1 := syscall.a1
-			v = reflect.NewAt(outType, unsafe.Pointer(&a1)).Elem()
+		return makeResults(args, getReturn(ty.Out(0), syscall, free), plan.errno, errno)
+	})
+	fn.Set(v)
+	return nil
+}
+
+// funcCallbacks holds the callbacks created for func arguments. A callback is kept for the lifetime of
+// the process once a function value is passed as a func argument, while the callback of a ScopedCallback
+// is shared by the calls in progress that pass the same function value and released when the last of
+// them returns. Since a callback keeps its function value alive, the address of a function value in
+// use is never reused by another one.
+var funcCallbacks struct {
+	lock sync.Mutex
+	m    map[funcCallbackKey]*funcCallbackEntry
+}
+
+type funcCallbackKey struct {
+	fn  unsafe.Pointer // the closure pointer of the function value
+	typ reflect.Type
+}
+
+type funcCallbackEntry struct {
+	ptr       uintptr
+	refs      int  // the number of calls in progress that hold the callback of a ScopedCallback
+	permanent bool // whether the function value was passed as a func argument
+}
+
+// funcCallback returns the callback of the function value fn, creating it with NewCallback on first use.
+// If scoped is true, the callback is only held for the duration of a call and the returned key must be
+// passed to releaseFuncCallback once the call returns. Otherwise the callback is kept and the key is zero.
+func funcCallback(fn any, scoped bool) (uintptr, funcCallbackKey) {
+	key := funcCallbackKey{
+		fn:  (*[2]unsafe.Pointer)(unsafe.Pointer(&fn))[1],
+		typ: reflect.TypeOf(fn),
+	}
+	funcCallbacks.lock.Lock()
+	defer funcCallbacks.lock.Unlock()
+	e, ok := funcCallbacks.m[key]
+	if !ok {
+		e = &funcCallbackEntry{ptr: NewCallback(fn)}
+		if funcCallbacks.m == nil {
+			funcCallbacks.m = make(map[funcCallbackKey]*funcCallbackEntry)
+		}
+		funcCallbacks.m[key] = e
+	}
+	if !scoped {
+		e.permanent = true
+		return e.ptr, funcCallbackKey{}
+	}
+	e.refs++
+	return e.ptr, key
+}
+
+// scopedCallback returns the callback of the ScopedCallback v for the duration of a call, see funcCallback.
+func scopedCallback(v reflect.Value) (uintptr, funcCallbackKey) {
+	return funcCallback(v.Interface().(scopedCallbackArg).scopedFunc(), true)
+}
+
+// releaseFuncCallback releases the callback held by a call with the key returned by funcCallback.
+// The callback is released with ReleaseCallback when no other call holds it and its function value
+// was never passed as a func argument. On Windows, where callbacks cannot be released, it is kept
+// for the next call that passes the same function value.
+func releaseFuncCallback(key funcCallbackKey) {
+	funcCallbacks.lock.Lock()
+	defer funcCallbacks.lock.Unlock()
+	e := funcCallbacks.m[key]
+	e.refs--
+	if e.refs > 0 || e.permanent || runtime.GOOS == "windows" {
+		return
+	}
+	delete(funcCallbacks.m, key)
+	ReleaseCallback(e.ptr)
+}
+
+// checkFuncType returns an error if the function type ty cannot be used to call a C function.
+// It also checks how many registers and stack the function will use
+// to avoid crashing with too many arguments.
+func checkFuncType(ty reflect.Type) error {
+	switch ty.NumOut() {
+	case 0, 1:
+	case 2:
+		if out := ty.Out(1); out != reflect.TypeFor[syscall.Errno]() && out != reflect.TypeFor[error]() {
+			return &UnsupportedTypeError{Arg: -1, Type: out, msg: "purego: the second return value must be syscall.Errno or error"}
+		}
+		if err := errnoSupported(); err != nil {
+			return err
+		}
+	default:
+		return errors.New("purego: function can only return zero or one values, and an optional errno")
+	}
+	if ty.NumOut() > 0 {
+		if isPinnedType(ty.Out(0)) {
+			return &UnsupportedTypeError{Arg: -1, Type: ty.Out(0), msg: "purego: Pinned cannot be returned"}
+		}
+		switch out := ty.Out(0); out.Kind() {
+		case reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool,
+			reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Float32, reflect.Float64,
+			reflect.Func, reflect.Struct, reflect.Complex64, reflect.Complex128:
+		case reflect.Slice:
+			if out.Elem().Kind() != reflect.String {
+				return &UnsupportedTypeError{Arg: -1, Type: out, msg: "purego: only []string can be returned as a slice"}
+			}
+		default:
+			return &UnsupportedTypeError{Arg: -1, Type: out, msg: "purego: unsupported return kind: " + out.Kind().String()}
+		}
+	}
+	if ty.NumOut() > 0 && (ty.Out(0).Kind() == reflect.Float32 || ty.Out(0).Kind() == reflect.Float64) &&
+		runtime.GOARCH != "arm" && runtime.GOARCH != "arm64" && runtime.GOARCH != "386" && runtime.GOARCH != "amd64" && runtime.GOARCH != "loong64" && runtime.GOARCH != "ppc64le" && runtime.GOARCH != "riscv64" && runtime.GOARCH != "s390x" {
+		return &UnsupportedTypeError{Arg: -1, Type: ty.Out(0), msg: "purego: float returns are not supported"}
+	}
+	var ints int
+	var floats int
+	floatArgRegs := numOfFloatRegisters()
+	var stack int
+	for i := 0; i < ty.NumIn(); i++ {
+		arg := ty.In(i)
+		if isScopedCallbackType(arg) {
+			if arg = arg.Field(0).Type; arg.Kind() != reflect.Func {
+				return &UnsupportedTypeError{Arg: i, Type: ty.In(i), msg: "purego: ScopedCallback must hold a function"}
+			}
+		}
+		switch arg.Kind() {
 		case reflect.Func:
-			// wrap this C function in a nicely typed Go function
-			v = reflect.New(outType)
-			RegisterFunc(v.Interface(), syscall.a1)
-		case reflect.String:
-			v.SetString(strings.GoString(syscall.a1))
-		case reflect.Float32:
-			// NOTE: syscall.r2 is only the floating return value on 64bit platforms.
-			// On 32bit platforms syscall.r2 is the upper part of a 64bit return.
-			// On 386, x87 FPU returns floats as float64 in ST(0), so we read as float64 and convert.
-			// On PPC64LE, C ABI converts float32 to double in FPR, so we read as float64.
-			// On S390X (big-endian), float32 is in upper 32 bits of the 64-bit FP register.
-			switch runtime.GOARCH {
-			case "386":
-				v.SetFloat(math.Float64frombits(uint64(syscall.f1) | (uint64(syscall.f2) << 32)))
-			case "ppc64le":
-				v.SetFloat(math.Float64frombits(uint64(syscall.f1)))
-			case "s390x":
-				// S390X is big-endian: float32 in upper 32 bits of 64-bit register
-				v.SetFloat(float64(math.Float32frombits(uint32(syscall.f1 >> 32))))
-			default:
-				v.SetFloat(float64(math.Float32frombits(uint32(syscall.f1))))
+			// This only does preliminary testing to ensure the CDecl argument
+			// is the first argument. Full testing is done when the callback is actually
+			// created in NewCallback.
+			for j := 0; j < arg.NumIn(); j++ {
+				in := arg.In(j)
+				if !in.AssignableTo(reflect.TypeFor[CDecl]()) {
+					continue
+				}
+				if j != 0 {
+					return &UnsupportedTypeError{Arg: i, Type: arg, msg: "purego: CDecl must be the first argument"}
+				}
 			}
-		case reflect.Float64:
-			// NOTE: syscall.r2 is only the floating return value on 64bit platforms.
-			// On 32bit platforms syscall.r2 is the upper part of a 64bit return.
-			if is32bit {
-				v.SetFloat(math.Float64frombits(uint64(syscall.f1) | (uint64(syscall.f2) << 32)))
+		case reflect.String, reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Pointer, reflect.UnsafePointer,
+			reflect.Slice, reflect.Bool:
+			if ints < numOfIntegerRegisters() {
+				ints++
+			} else {
+				stack++
+			}
+		case reflect.Float32, reflect.Float64:
+			if floats < floatArgRegs {
+				floats++
+			} else {
+				stack++
+			}
+		case reflect.Struct, reflect.Complex64, reflect.Complex128:
+			if isPinnedType(arg) || isNullStringType(arg) {
+				// A Pinned or a NullString is passed as a pointer.
+				if ints < numOfIntegerRegisters() {
+					ints++
+				} else {
+					stack++
+				}
+				break
+			}
+			err := checkStruct(i, arg, func() {
+				if !isLongDoubleType(arg) && !isInt128Type(arg) && !isVectorType(arg) {
+					ensureStructSupported()
+				}
+				layout := cLayoutType(arg)
+				if layout != arg {
+					ensureComplexSupported()
+				}
+				longDouble, int128, vector := containsLongDouble(arg), containsInt128(arg), containsVector(arg)
+				if containsUnion(arg) || longDouble || int128 || vector {
+					checkStructFieldsSupported(arg)
+				}
+				if arg.Size() == 0 && runtime.GOOS != "windows" {
+					// On Windows an empty struct still consumes one argument slot.
+					return
+				}
+				addInt := func(u uintptr) {
+					ints++
+				}
+				addFloat := func(u uintptr) {
+					floats++
+				}
+				addStack := func(u uintptr) {
+					stack++
+				}
+				addQuad := func(lo, hi uintptr) {
+					floats++
+				}
+				if longDouble && addLongDouble(reflect.New(layout).Elem(), addInt, addStack, addQuad, &ints, &floats, &stack) {
+					return
+				}
+				if int128 && addInt128(reflect.New(layout).Elem(), addInt, addStack, &ints, &stack) {
+					return
+				}
+				if vector && addVector(reflect.New(layout).Elem(), addStack, addQuad, &floats, &stack) {
+					return
+				}
+				_ = addStruct(reflect.New(layout).Elem(), &ints, &floats, &stack, addInt, addFloat, addStack, nil)
+			})
+			if err != nil {
+				return err
+			}
+			if debug.layoutcheck && arg.Kind() == reflect.Struct {
+				if err := CheckLayout(arg, nil); err != nil {
+					return err
+				}
+			}
+		default:
+			return &UnsupportedTypeError{Arg: i, Type: arg, msg: "purego: unsupported kind " + arg.Kind().String()}
+		}
+	}
+	if ty.NumOut() > 0 && isStructResult(ty.Out(0)) {
+		outType := ty.Out(0)
+		err := checkStruct(-1, outType, func() {
+			if isLongDoubleType(outType) {
+				ensureLongDoubleSupported()
+				return
+			}
+			if isInt128Type(outType) {
+				ensureInt128Supported()
+				return
+			}
+			if isVectorType(outType) {
+				ensureVectorSupported()
+				return
+			}
+			ensureStructSupported()
+			if isComplexKind(outType.Kind()) {
+				ensureComplexSupported()
+				return
+			}
+			checkStructFieldsSupported(outType)
+		})
+		if err != nil {
+			return err
+		}
+		if debug.layoutcheck && outType.Kind() == reflect.Struct {
+			if err := CheckLayout(outType, nil); err != nil {
+				return err
+			}
+		}
+		if structReturnInMemory(outType) {
+			// A struct returned in memory is allocated by the caller and its
+			// pointer is passed as a hidden first integer argument. When the
+			// integer registers are already full, prepending it spills a
+			// regular argument onto the stack.
+			if ints < numOfIntegerRegisters() {
+				ints++
 			} else {
-				v.SetFloat(math.Float64frombits(uint64(syscall.f1)))
+				stack++
+			}
+		}
+	}
+
+	argsLimit := maxArgs
+	sizeOfStack := argsLimit - numOfIntegerRegisters()
+	if runtime.GOOS == "windows" {
+		if ints+floats+stack > argsLimit {
+			return ErrTooManyArguments
+		}
+	} else if isDarwin && runtime.GOARCH == "arm64" {
+		// On Darwin ARM64, use byte-based validation since arguments pack efficiently.
+		// See https://developer.apple.com/documentation/xcode/writing-arm64-code-for-apple-platforms
+		stackBytes := estimateStackBytes(ty)
+		maxStackBytes := sizeOfStack * 8
+		if stackBytes > maxStackBytes {
+			return ErrTooManyArguments
+		}
+	} else {
+		if stack > sizeOfStack {
+			return ErrTooManyArguments
+		}
+	}
+	return nil
+}
+
+// checkStruct runs check, which validates the struct type ty of argument arg, and converts a panic
+// raised by it into an [*UnsupportedTypeError]. The struct rules are implemented per architecture
+// and report unsupported fields by panicking while placing the struct.
+func checkStruct(arg int, ty reflect.Type, check func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, ok := r.(string)
+			if !ok {
+				panic(r)
+			}
+			err = &UnsupportedTypeError{Arg: arg, Type: ty, msg: msg}
+		}
+	}()
+	check()
+	return nil
+}
+
+// isStructResult reports whether a result of type t is returned like a C struct, which is
+// the case for complex numbers and for structs other than NullString.
+func isStructResult(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isNullStringType(t) || isComplexKind(t.Kind())
+}
+
+// callPlans caches the callPlan of every function type passed to RegisterFunc.
+var callPlans sync.Map // map[reflect.Type]*callPlan
+
+// slotRef is the location of a single pointer-sized value passed to a C function.
+type slotRef struct {
+	float bool // index refers to the float registers instead of sysargs
+	index int
+}
+
+// argPlan is the location of a single argument of a function registered with RegisterFunc.
+type argPlan struct {
+	typ  reflect.Type
+	kind reflect.Kind
+	// cstring is true for a NullString or a *string, which is passed as a C string or NULL.
+	cstring bool
+	// scoped is true for a ScopedCallback, whose callback is released when the call returns.
+	// typ and kind are then those of its function.
+	scoped bool
+	// slots holds where the argument is placed. Only float64 on 32bit platforms
+	// uses the second slot, for the upper half of the value.
+	slots [2]slotRef
+}
+
+// callPlan is the register and stack assignment of a function type registered
+// with RegisterFunc. It is computed once per signature so that each call only
+// has to copy its arguments into place.
+type callPlan struct {
+	// fixed is true when every argument has a known location before the call.
+	// Signatures with struct or []any arguments, or which pack arguments onto
+	// the stack on Darwin ARM64, are placed argument by argument instead.
+	fixed bool
+	args  []argPlan
+	out   reflect.Type // nil when the function has no return value
+	// retInMemory is true when a struct return value is written through a
+	// hidden pointer passed as the first integer argument.
+	retInMemory bool
+	// retR8 is true when a struct return value is written through the
+	// pointer in R8 on arm64.
+	retR8 bool
+	// numStack is the number of sysargs used when every argument is passed
+	// in order as on Windows amd64, 386 and arm.
+	numStack int
+	// errno is the type of the second return value, syscall.Errno or error,
+	// or nil when the function does not return errno.
+	errno reflect.Type
+	// complex is true when an argument is or contains a complex number,
+	// which the argument by argument path converts to the struct of its parts first.
+	complex bool
+	// pointers is true when an argument is a Pinned, a NullString, a *string or a ScopedCallback,
+	// which are passed as pointers that the argument by argument path converts first.
+	pointers bool
+	// longDouble is true when an argument is or contains a LongDouble,
+	// which the argument by argument path places with addLongDouble.
+	longDouble bool
+	// int128 is true when an argument is or contains an Int128 or a Uint128,
+	// which the argument by argument path places with addInt128.
+	int128 bool
+	// vector is true when an argument is or contains a Vec128,
+	// which the argument by argument path places with addVector.
+	vector bool
+}
+
+// planFor returns the callPlan of the function type ty.
+func planFor(ty reflect.Type) *callPlan {
+	if p, ok := callPlans.Load(ty); ok {
+		return p.(*callPlan)
+	}
+	p, _ := callPlans.LoadOrStore(ty, newCallPlan(ty))
+	return p.(*callPlan)
+}
+
+func newCallPlan(ty reflect.Type) *callPlan {
+	p := &callPlan{}
+	if ty.NumOut() > 0 {
+		p.out = ty.Out(0)
+	}
+	if ty.NumOut() > 1 {
+		p.errno = ty.Out(1)
+	}
+	// sequential is true on Windows amd64, 386, and arm where every argument
+	// is passed in order through syscall.SyscallN.
+	sequential := runtime.GOOS == "windows" && runtime.GOARCH != "arm64"
+	var numInts, numFloats, numStack int
+	stackSlot := func() (slotRef, bool) {
+		index := numStack
+		if !sequential {
+			index += numOfIntegerRegisters()
+		}
+		if index >= maxArgs {
+			return slotRef{}, false
+		}
+		numStack++
+		return slotRef{index: index}, true
+	}
+	intSlot := func() (slotRef, bool) {
+		if sequential {
+			return stackSlot()
+		}
+		if numInts < numOfIntegerRegisters() {
+			numInts++
+			return slotRef{index: numInts - 1}, true
+		}
+		if isDarwin && runtime.GOARCH == "arm64" {
+			// Stack arguments are packed by their C alignment.
+			return slotRef{}, false
+		}
+		return stackSlot()
+	}
+	floatSlot := func() (slotRef, bool) {
+		if sequential {
+			return stackSlot()
+		}
+		if numFloats < numOfFloatRegisters() {
+			numFloats++
+			return slotRef{float: true, index: numFloats - 1}, true
+		}
+		if isDarwin && runtime.GOARCH == "arm64" {
+			// Stack arguments are packed by their C alignment.
+			return slotRef{}, false
+		}
+		return stackSlot()
+	}
+
+	if p.out != nil && isStructResult(p.out) {
+		out := cLayoutType(p.out)
+		if structReturnInMemory(out) {
+			p.retInMemory = true
+			// The hidden pointer always takes the first integer slot.
+			if _, ok := intSlot(); !ok {
+				return p
 			}
+		} else if runtime.GOARCH == "arm64" && out.Size() > maxRegAllocStructSize {
+			isAllFloats, numFields := isAllSameFloat(out)
+			p.retR8 = !isAllFloats || numFields > 4
+		}
+	}
+	for i := 0; i < ty.NumIn(); i++ {
+		in := ty.In(i)
+		if isPinnedType(in) || isNullStringType(in) || isStringPointerType(in) || isScopedCallbackType(in) {
+			p.pointers = true
+		} else if cLayoutType(in) != in {
+			p.complex = true
+		}
+		p.longDouble = p.longDouble || containsLongDouble(in)
+		p.int128 = p.int128 || containsInt128(in)
+		p.vector = p.vector || containsVector(in)
+	}
+	p.args = make([]argPlan, ty.NumIn())
+	for i := range p.args {
+		in := ty.In(i)
+		a := &p.args[i]
+		a.typ = in
+		a.kind = in.Kind()
+		a.cstring = isNullStringType(in) || isStringPointerType(in)
+		var ok bool
+		switch a.kind {
+		case reflect.String, reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Pointer, reflect.UnsafePointer,
+			reflect.Bool, reflect.Func:
+			a.slots[0], ok = intSlot()
+		case reflect.Slice:
+			if in == reflect.TypeFor[[]any]() {
+				// The elements are expanded into arguments at call time.
+				return p
+			}
+			a.slots[0], ok = intSlot()
 		case reflect.Struct:
-			v = getStruct(outType, *syscall)
+			if isScopedCallbackType(in) {
+				// A ScopedCallback holds just its function value.
+				a.typ = in.Field(0).Type
+				a.kind = reflect.Func
+				a.scoped = true
+			} else if !isPinnedType(in) && !isNullStringType(in) {
+				return p
+			}
+			a.slots[0], ok = intSlot()
+		case reflect.Float32:
+			a.slots[0], ok = floatSlot()
+		case reflect.Float64:
+			a.slots[0], ok = floatSlot()
+			if ok && unsafe.Sizeof(uintptr(0)) == 4 {
+				a.slots[1], ok = floatSlot()
+			}
 		default:
-			panic("purego: unsupported return kind: " + outType.Kind().String())
+			return p
 		}
-		if len(args) > 0 {
-			// reuse args slice instead of allocating one when possible
-			args[0] = v
-			return args[:1]
+		if !ok {
+			return p
+		}
+	}
+	p.numStack = numStack
+	p.fixed = true
+	return p
+}
+
+// call calls cfn with args placed in the locations computed by newCallPlan and frees
+// an OwnedString result with free. It must only be called when p.fixed is true.
+func (p *callPlan) call(cfn, free uintptr, args []reflect.Value) []reflect.Value {
+	v, errno := p.invoke(cfn, free, args, nil, nil)
+	if p.out == nil {
+		return nil
+	}
+	return makeResults(args, v, p.errno, errno)
+}
+
+// invoke calls cfn with the arguments args, or the arguments pointed to by ptrs when args is nil,
+// placed in the locations computed by newCallPlan. A function or struct result, and any result
+// when ret is nil, is returned. Any other result is stored at ret. It must only be called when
+// p.fixed is true.
+func (p *callPlan) invoke(cfn, free uintptr, args []reflect.Value, ptrs []unsafe.Pointer, ret unsafe.Pointer) (reflect.Value, uintptr) {
+	var sysargs [maxArgs]uintptr
+	// Use maxArgs instead of numOfFloatRegisters() to keep this code path allocation-free.
+	var floats [maxArgs]uintptr
+	// cstrings keeps the C strings created for string arguments alive
+	// without allocating when there are none. It is indexed by sysargs slot.
+	var cstrings [maxArgs]*byte
+	// structRet is the struct written to by the C function when it is not returned in registers.
+	// It is allocated on the heap since ret may point to the stack which can move before the call.
+	var structRet reflect.Value
+	var arm64_r8 uintptr
+	if p.retInMemory {
+		// The caller allocates the return value and passes its pointer
+		// as a hidden first integer argument.
+		structRet = reflect.New(p.out)
+		sysargs[0] = structRet.Pointer()
+	} else if p.retR8 {
+		structRet = reflect.New(p.out)
+		arm64_r8 = structRet.Pointer()
+	}
+	put := func(s slotRef, x uintptr) {
+		if s.float {
+			floats[s.index] = x
 		} else {
-			return []reflect.Value{v}
+			sysargs[s.index] = x
 		}
-	})
-	fn.Set(v)
+	}
+	// callbacks holds the callbacks created for ScopedCallback arguments, which are released
+	// once the C function returns. It is indexed by sysargs slot.
+	var callbacks [maxArgs]funcCallbackKey
+	// pinners holds the pinners of the arrays of C strings created for []string arguments,
+	// which are unpinned once the C function returns. It is indexed by sysargs slot.
+	var pinners [maxArgs]*runtime.Pinner
+	defer func() {
+		for _, key := range callbacks {
+			if key.typ != nil {
+				releaseFuncCallback(key)
+			}
+		}
+		for _, pinner := range pinners {
+			if pinner != nil {
+				pinner.Unpin()
+			}
+		}
+	}()
+	for i, a := range p.args {
+		if a.kind == reflect.Func {
+			var fn any
+			switch {
+			case args != nil && a.scoped:
+				fn = args[i].Interface().(scopedCallbackArg).scopedFunc()
+			case args != nil:
+				fn = args[i].Interface()
+			default:
+				// Copy the function value so that the argument does not escape.
+				f := *(*unsafe.Pointer)(ptrs[i])
+				fn = reflect.NewAt(a.typ, unsafe.Pointer(&f)).Elem().Interface()
+			}
+			var ptr uintptr
+			ptr, callbacks[a.slots[0].index] = funcCallback(fn, a.scoped)
+			put(a.slots[0], ptr)
+			continue
+		}
+		var v reflect.Value
+		if args != nil {
+			v = args[i]
+		} else {
+			v = reflect.NewAt(a.typ, ptrs[i]).Elem()
+		}
+		words, cstr, pinner := argWords(v)
+		cstrings[a.slots[0].index] = cstr
+		pinners[a.slots[0].index] = pinner
+		put(a.slots[0], words[0])
+		if a.kind == reflect.Float64 && unsafe.Sizeof(uintptr(0)) == 4 {
+			put(a.slots[1], words[1])
+		}
+	}
+
+	var errnoLoc unsafe.Pointer
+	if p.errno != nil {
+		errnoLoc = beginErrno()
+	}
+	var syscall *syscallArgs
+	if runtime.GOOS == "windows" && runtime.GOARCH != "arm64" {
+		// Windows amd64, 386, and arm use syscall.SyscallN.
+		syscall = thePool.Get().(*syscallArgs)
+		syscall.a1, syscall.a2, syscall.a3 = syscall_syscallN(cfn, sysargs[:p.numStack]...)
+		syscall.f1 = syscall.a2 // on amd64 a2 stores the float return. On 32bit platforms floats aren't support
+	} else {
+		syscall = syscall_SyscallN(cfn, sysargs[:], floats[:], arm64_r8)
+	}
+	var errno uintptr
+	if p.errno != nil {
+		errno = endErrno(errnoLoc, syscall)
+	}
+	runtime.KeepAlive(cstrings)
+	runtime.KeepAlive(args)
+	var v reflect.Value
+	switch {
+	case p.out == nil:
+	case ret != nil && p.out.Kind() != reflect.Func && !isStructResult(p.out):
+		setReturn(p.out, syscall, ret, free)
+	default:
+		v = getReturn(p.out, syscall, free)
+	}
+	runtime.KeepAlive(structRet)
+	thePool.Put(syscall)
+	return v, errno
 }
 
-func addValue(v reflect.Value, keepAlive []any, addInt func(x uintptr), addFloat func(x uintptr), addStack func(x uintptr), numInts *int, numFloats *int, numStack *int) []any {
+// argWords returns the words that pass the argument v, which is neither a function nor a struct
+// other than a Pinned or a NullString, and the C string that must be kept alive until the call
+// returns, if any. Only a float64 on 32bit platforms uses the second word. The array of C strings
+// of a []string is pinned by the returned pinner, which must be unpinned after the call returns.
+func argWords(v reflect.Value) (words [2]uintptr, cstr *byte, pinner *runtime.Pinner) {
 	const is32bit = unsafe.Sizeof(uintptr(0)) == 4
 	switch v.Kind() {
 	case reflect.String:
-		ptr := strings.CString(v.String())
-		keepAlive = append(keepAlive, ptr)
-		addInt(uintptr(unsafe.Pointer(ptr)))
+		cstr = strings.CString(v.String())
 	case reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		addInt(uintptr(v.Uint()))
+		words[0] = uintptr(v.Uint())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		addInt(uintptr(v.Int()))
-	case reflect.Pointer, reflect.UnsafePointer, reflect.Slice:
-		// There is no need to keepAlive this pointer separately because it is kept alive in the args variable
-		addInt(v.Pointer())
-	case reflect.Func:
-		addInt(NewCallback(v.Interface()))
+		words[0] = uintptr(v.Int())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			// A pointer to the first element keeps the array and the strings it points to alive.
+			cstr, pinner = cStringArray(v)
+			break
+		}
+		// There is no need to keepAlive this pointer separately because the caller holds the argument.
+		words[0] = uintptr(v.UnsafePointer())
+	case reflect.Pointer, reflect.UnsafePointer:
+		if isStringPointerType(v.Type()) {
+			cstr = nullableCString(v)
+			break
+		}
+		// There is no need to keepAlive this pointer separately because the caller holds the argument.
+		words[0] = uintptr(v.UnsafePointer())
 	case reflect.Bool:
 		if v.Bool() {
-			addInt(1)
-		} else {
-			addInt(0)
+			words[0] = 1
 		}
 	case reflect.Float32:
+		words[0] = float32Bits(v.Float())
+	case reflect.Float64:
+		bits := math.Float64bits(v.Float())
+		words[0] = uintptr(bits)
+		if is32bit {
+			words[1] = uintptr(bits >> 32)
+		}
+	case reflect.Struct:
+		if isPinnedType(v.Type()) {
+			words[0] = pinnedPointer(v)
+			break
+		}
+		// Only a NullString is left, which is passed as a C string.
+		cstr = nullableCString(v)
+	default:
+		panic("purego: unsupported kind: " + v.Kind().String())
+	}
+	if cstr != nil {
+		words[0] = uintptr(unsafe.Pointer(cstr))
+	}
+	return words, cstr, pinner
+}
+
+// makeResults returns the results of a registered function, v and errno if errnoType is not nil.
+func makeResults(args []reflect.Value, v reflect.Value, errnoType reflect.Type, errno uintptr) []reflect.Value {
+	n := 1
+	if errnoType != nil {
+		n = 2
+	}
+	// reuse args slice instead of allocating one when possible
+	results := args
+	if len(results) < n {
+		results = make([]reflect.Value, n)
+	}
+	results[0] = v
+	if errnoType != nil {
+		results[1] = errnoValue(errnoType, errno)
+	}
+	return results[:n]
+}
+
+// errnoValue converts errno into a Go value of type t which is either syscall.Errno or error.
+func errnoValue(t reflect.Type, errno uintptr) reflect.Value {
+	if t == reflect.TypeFor[syscall.Errno]() {
+		return reflect.ValueOf(syscall.Errno(errno))
+	}
+	v := reflect.New(t).Elem()
+	if errno != 0 {
+		v.Set(reflect.ValueOf(syscall.Errno(errno)))
+	}
+	return v
+}
+
+// getReturn converts the values returned by the C function in syscall into a
+// Go value of type outType. free is the C function that frees an OwnedString.
+func getReturn(outType reflect.Type, syscall *syscallArgs, free uintptr) reflect.Value {
+	switch outType.Kind() {
+	case reflect.Func:
+		// wrap this C function in a nicely typed Go function
+		v := reflect.New(outType)
+		RegisterFunc(v.Interface(), syscall.a1)
+		return v.Elem()
+	}
+	if isStructResult(outType) {
+		if v, ok := longDoubleResult(outType, syscall); ok {
+			return v
+		}
+		if v, ok := vectorResult(outType, syscall); ok {
+			return v
+		}
+		return fromCLayout(outType, getStruct(cLayoutType(outType), *syscall))
+	}
+	v := reflect.New(outType)
+	setReturn(outType, syscall, v.UnsafePointer(), free)
+	return v.Elem()
+}
+
+// setReturn converts the value returned by the C function in syscall into a
+// Go value of type outType and stores it at ptr. Function and struct return
+// values other than NullString are only handled by getReturn. An OwnedString is
+// freed with free once it is copied.
+func setReturn(outType reflect.Type, syscall *syscallArgs, ptr unsafe.Pointer, free uintptr) {
+	const is32bit = unsafe.Sizeof(uintptr(0)) == 4
+	switch outType.Kind() {
+	case reflect.Uintptr, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch outType.Size() {
+		case 1:
+			*(*uint8)(ptr) = uint8(syscall.a1)
+		case 2:
+			*(*uint16)(ptr) = uint16(syscall.a1)
+		case 4:
+			*(*uint32)(ptr) = uint32(syscall.a1)
+		default:
+			*(*uint64)(ptr) = uint64(syscall.a1)
+		}
+	case reflect.Bool:
+		*(*bool)(ptr) = byte(syscall.a1) != 0
+	case reflect.Pointer, reflect.UnsafePointer:
+		if isStringPointerType(outType) {
+			// A *string is nil for NULL and points to a copy of the string otherwise.
+			if syscall.a1 == 0 {
+				*(**string)(ptr) = nil
+			} else {
+				s := strings.GoString(syscall.a1)
+				*(**string)(ptr) = &s
+			}
+			break
+		}
+		// Copy syscall.a1 into a local variable to prevent the result
+		// from holding a pointer to the pooled syscallArgs field.
+		// We take the address and then dereference it to trick go vet from creating a possible miss-use of unsafe.Pointer
+		a1 := syscall.a1
+		*(*unsafe.Pointer)(ptr) = *(*unsafe.Pointer)(unsafe.Pointer(&a1))
+	case reflect.String:
+		*(*string)(ptr) = strings.GoString(syscall.a1)
+		if free != 0 && syscall.a1 != 0 && isOwnedStringType(outType) {
+			freeCString(free, syscall.a1)
+		}
+	case reflect.Slice:
+		// Only []string is accepted by checkFuncType.
+		*(*[]string)(ptr) = goStrings(syscall.a1, -1)
+	case reflect.Struct:
+		// Only NullString is handled here.
+		*(*NullString)(ptr) = goNullString(syscall.a1)
+	case reflect.Float32:
+		// NOTE: syscall.r2 is only the floating return value on 64bit platforms.
+		// On 32bit platforms syscall.r2 is the upper part of a 64bit return.
+		// On 386, x87 FPU returns floats as float64 in ST(0), so we read as float64 and convert.
+		// On PPC64LE, C ABI converts float32 to double in FPR, so we read as float64.
+		// On S390X (big-endian), float32 is in upper 32 bits of the 64-bit FP register.
 		switch runtime.GOARCH {
+		case "386":
+			*(*float32)(ptr) = float32(math.Float64frombits(uint64(syscall.f1) | (uint64(syscall.f2) << 32)))
 		case "ppc64le":
-			// A single-precision argument occupies a floating-point register in double format on Power.
-			addFloat(uintptr(math.Float64bits(v.Float())))
+			*(*float32)(ptr) = float32(math.Float64frombits(uint64(syscall.f1)))
 		case "s390x":
-			// S390X big-endian: float32 goes in the upper 32 bits of the 64-bit FP register.
-			addFloat(uintptr(math.Float32bits(float32(v.Float()))) << 32)
+			// S390X is big-endian: float32 in upper 32 bits of 64-bit register
+			*(*float32)(ptr) = math.Float32frombits(uint32(uint64(syscall.f1) >> 32))
 		default:
-			addFloat(uintptr(math.Float32bits(float32(v.Float()))))
+			*(*float32)(ptr) = math.Float32frombits(uint32(syscall.f1))
 		}
 	case reflect.Float64:
+		// NOTE: syscall.r2 is only the floating return value on 64bit platforms.
+		// On 32bit platforms syscall.r2 is the upper part of a 64bit return.
 		if is32bit {
-			bits := math.Float64bits(v.Float())
-			addFloat(uintptr(bits))
-			addFloat(uintptr(bits >> 32))
+			*(*float64)(ptr) = math.Float64frombits(uint64(syscall.f1) | (uint64(syscall.f2) << 32))
 		} else {
-			addFloat(uintptr(math.Float64bits(v.Float())))
+			*(*float64)(ptr) = math.Float64frombits(uint64(syscall.f1))
 		}
+	default:
+		panic("purego: unsupported return kind: " + outType.Kind().String())
+	}
+}
+
+func addValue(v reflect.Value, keepAlive []any, addInt func(x uintptr), addFloat func(x uintptr), addStack func(x uintptr), numInts *int, numFloats *int, numStack *int) []any {
+	const is32bit = unsafe.Sizeof(uintptr(0)) == 4
+	switch v.Kind() {
+	case reflect.Func:
+		ptr, _ := funcCallback(v.Interface(), false)
+		addInt(ptr)
+		return keepAlive
 	case reflect.Struct:
-		keepAlive = addStruct(v, numInts, numFloats, numStack, addInt, addFloat, addStack, keepAlive)
+		if isScopedCallbackType(v.Type()) {
+			ptr, key := scopedCallback(v)
+			addInt(ptr)
+			return append(keepAlive, key)
+		}
+		if isPinnedType(v.Type()) || isNullStringType(v.Type()) {
+			break
+		}
+		if containsLongDouble(v.Type()) {
+			// Fixed arguments holding a LongDouble are placed by addLongDouble before they get here.
+			panic("purego: LongDouble cannot be passed in ...any")
+		}
+		if containsInt128(v.Type()) {
+			// Fixed arguments holding an Int128 or a Uint128 are placed by addInt128 before they get here.
+			panic("purego: Int128 and Uint128 cannot be passed in ...any")
+		}
+		if containsVector(v.Type()) {
+			// Fixed arguments holding a Vec128 are placed by addVector before they get here.
+			panic("purego: Vec128 cannot be passed in ...any")
+		}
+		return addStruct(toCLayout(v), numInts, numFloats, numStack, addInt, addFloat, addStack, keepAlive)
+	case reflect.Complex64, reflect.Complex128:
+		return addStruct(toCLayout(v), numInts, numFloats, numStack, addInt, addFloat, addStack, keepAlive)
+	}
+	words, cstr, pinner := argWords(v)
+	if cstr != nil {
+		keepAlive = append(keepAlive, cstr)
+	}
+	if pinner != nil {
+		keepAlive = append(keepAlive, pinner)
+	}
+	switch v.Kind() {
+	case reflect.Float32:
+		addFloat(words[0])
+	case reflect.Float64:
+		addFloat(words[0])
+		if is32bit {
+			addFloat(words[1])
+		}
 	default:
-		panic("purego: unsupported kind: " + v.Kind().String())
+		addInt(words[0])
 	}
 	return keepAlive
 }
 
+// cStringArray returns a pointer to a NULL-terminated array of C strings copied from the []string v,
+// or nil if v is nil. The array keeps the strings alive. Since the array holds Go pointers, the cgo
+// rules only allow passing it to C while the strings are pinned, so the array and the strings are
+// pinned by the returned pinner, which must be unpinned after the call returns.
+func cStringArray(v reflect.Value) (*byte, *runtime.Pinner) {
+	if v.IsNil() {
+		return nil, nil
+	}
+	pinner := new(runtime.Pinner)
+	arr := make([]*byte, v.Len()+1)
+	for i := range v.Len() {
+		arr[i] = strings.CString(v.Index(i).String())
+		pinner.Pin(arr[i])
+	}
+	pinner.Pin(&arr[0])
+	return (*byte)(unsafe.Pointer(&arr[0])), pinner
+}
+
+// goStrings copies the strings of the array of C strings at p into Go memory. The array holds n strings,
+// or is terminated by NULL if n is negative. goStrings returns nil if p is NULL.
+func goStrings(p uintptr, n int) []string {
+	if p == 0 {
+		return nil
+	}
+	// We take the address and then dereference it to trick go vet from creating a possible misuse of unsafe.Pointer
+	arr := *(*unsafe.Pointer)(unsafe.Pointer(&p))
+	strs := make([]string, 0, max(n, 0))
+	for i := 0; n < 0 || i < n; i++ {
+		s := *(*uintptr)(unsafe.Add(arr, uintptr(i)*unsafe.Sizeof(uintptr(0))))
+		if n < 0 && s == 0 {
+			break
+		}
+		strs = append(strs, strings.GoString(s))
+	}
+	return strs
+}
+
+// addVariadic adds v as a variadic argument of a C function. It applies the C default argument
+// promotions and passes the value where the variadic calling convention of the platform expects it.
+func addVariadic(v reflect.Value, keepAlive []any, addInt func(x uintptr), addFloat func(x uintptr), addStack func(x uintptr), numInts *int, numFloats *int, numStack *int) []any {
+	switch v.Kind() {
+	case reflect.Float32:
+		// float is promoted to double.
+		v = reflect.ValueOf(v.Float())
+	case reflect.Struct:
+		if !isPinnedType(v.Type()) && !isNullStringType(v.Type()) && !isScopedCallbackType(v.Type()) {
+			panic("purego: struct variadic arguments are not supported")
+		}
+	case reflect.Complex64, reflect.Complex128:
+		panic("purego: complex variadic arguments are not supported")
+	}
+	switch {
+	case isDarwin && runtime.GOARCH == "arm64":
+		// Apple passes every variadic argument on the stack in its own 8-byte slot.
+		addInt = addStack
+		addFloat = addStack
+	case runtime.GOOS == "windows" && runtime.GOARCH == "arm64",
+		runtime.GOARCH == "loong64", runtime.GOARCH == "ppc64le", runtime.GOARCH == "riscv64":
+		// Variadic floating-point arguments are passed in the integer registers.
+		addFloat = addInt
+	case runtime.GOOS != "windows" && runtime.GOARCH == "arm":
+		addFloat = alignCoreFloat(v, addInt, addFloat, addStack, numInts, numStack)
+	}
+	return addValue(v, keepAlive, addInt, addFloat, addStack, numInts, numFloats, numStack)
+}
+
+// alignCoreFloat returns the function that adds v, which is any argument of a variadic C function
+// on 32-bit ARM, as a floating-point number. The base procedure call standard, which is used for
+// every argument of a variadic function including the fixed ones, passes a float in a core register
+// and a double in an even-odd pair of core registers or in an 8-byte aligned stack slot, so for
+// those it pads the core registers or the stack and returns addInt. Otherwise it returns addFloat.
+func alignCoreFloat(v reflect.Value, addInt, addFloat, addStack func(x uintptr), numInts, numStack *int) func(x uintptr) {
+	switch v.Kind() {
+	case reflect.Float32:
+		return addInt
+	case reflect.Float64:
+		if *numInts < numOfIntegerRegisters() && *numInts%2 == 1 {
+			addInt(0)
+		} else if *numInts >= numOfIntegerRegisters() && *numStack%2 == 1 {
+			addStack(0)
+		}
+		return addInt
+	}
+	return addFloat
+}
+
+// float32Bits returns the bits of a float32 argument as they are placed in a
+// floating-point register slot.
+func float32Bits(f float64) uintptr {
+	switch runtime.GOARCH {
+	case "ppc64le":
+		// A single-precision argument occupies a floating-point register in double format on Power.
+		return uintptr(math.Float64bits(f))
+	case "s390x":
+		// S390X big-endian: float32 goes in the upper 32 bits of the 64-bit FP register.
+		return uintptr(math.Float32bits(float32(f))) << 32
+	default:
+		return uintptr(math.Float32bits(float32(f)))
+	}
+}
+
 // maxRegAllocStructSize is the biggest a struct can be while still fitting in registers.
 // if it is bigger than this than enough space must be allocated on the heap and then passed into
 // the function as the first parameter on amd64 or in R8 on arm64.
@@ -465,6 +1521,20 @@ func addValue(v reflect.Value, keepAlive []any, addInt func(x uintptr), addFloat
 const maxRegAllocStructSize = 16
 
 func isAllSameFloat(ty reflect.Type) (allFloats bool, numFields int) {
+	if containsLongDouble(ty) {
+		// A long double fills a whole vector register on arm64.
+		n, ok := homogeneousLongDoubles(ty)
+		return ok, n
+	}
+	if containsVector(ty) {
+		// So does a vector.
+		n, ok := homogeneousVectors(ty)
+		return ok, n
+	}
+	if containsUnion(ty) {
+		_, n, ok := homogeneousFloats(ty)
+		return ok, n
+	}
 	allFloats = true
 	root := ty.Field(0).Type
 	for root.Kind() == reflect.Struct {
@@ -491,22 +1561,55 @@ func isAllSameFloat(ty reflect.Type) (allFloats bool, numFields int) {
 }
 
 func checkStructFieldsSupported(ty reflect.Type) {
-	for i := 0; i < ty.NumField(); i++ {
-		f := ty.Field(i).Type
-		if f.Kind() == reflect.Array {
-			f = f.Elem()
-		} else if f.Kind() == reflect.Struct {
-			checkStructFieldsSupported(f)
-			continue
+	if isLongDoubleType(ty) {
+		ensureLongDoubleSupported()
+		return
+	}
+	if isInt128Type(ty) {
+		ensureInt128Supported()
+		return
+	}
+	if isVectorType(ty) {
+		ensureVectorSupported()
+		return
+	}
+	if isUnionType(ty) {
+		checkUnionSupported(ty)
+		t1, t2 := unionMembers(ty)
+		if containsLongDouble(t1) || containsLongDouble(t2) {
+			panic("purego: LongDouble cannot be a member of a Union")
 		}
-		switch f.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-			reflect.Uintptr, reflect.Pointer, reflect.UnsafePointer, reflect.Float64, reflect.Float32,
-			reflect.Bool:
-		default:
-			panic(fmt.Sprintf("purego: struct field type %s is not supported", f))
+		if containsInt128(t1) || containsInt128(t2) {
+			panic("purego: Int128 and Uint128 cannot be a member of a Union")
+		}
+		if containsVector(t1) || containsVector(t2) {
+			panic("purego: Vec128 cannot be a member of a Union")
 		}
+		checkFieldSupported(t1)
+		checkFieldSupported(t2)
+		return
+	}
+	for i := 0; i < ty.NumField(); i++ {
+		checkFieldSupported(ty.Field(i).Type)
+	}
+}
+
+// checkFieldSupported panics if a struct field or union member of type f cannot be passed to C.
+func checkFieldSupported(f reflect.Type) {
+	if f.Kind() == reflect.Array {
+		f = f.Elem()
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Pointer, reflect.UnsafePointer, reflect.Float64, reflect.Float32,
+		reflect.Bool:
+	case reflect.Complex64, reflect.Complex128:
+		ensureComplexSupported()
+	case reflect.Struct:
+		checkStructFieldsSupported(f)
+	default:
+		panic(fmt.Sprintf("purego: struct field type %s is not supported", f))
 	}
 }
 
@@ -595,6 +1698,10 @@ func estimateStackBytes(ty reflect.Type) int {
 	for i := 0; i < ty.NumIn(); i++ {
 		arg := ty.In(i)
 		size := int(arg.Size())
+		if isPinnedType(arg) || isNullStringType(arg) || isScopedCallbackType(arg) {
+			// A Pinned, a NullString or a ScopedCallback is passed as a pointer.
+			size = int(unsafe.Sizeof(uintptr(0)))
+		}
 
 		// Check if this goes to register or stack
 		usesInt := arg.Kind() != reflect.Float32 && arg.Kind() != reflect.Float64
@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"unsafe"
 
@@ -159,6 +160,293 @@ func TestRegisterFunc_Floats(t *testing.T) {
 	}
 }
 
+func TestNewFunc(t *testing.T) {
+	if runtime.GOARCH != "arm" && runtime.GOARCH != "arm64" && runtime.GOARCH != "386" && runtime.GOARCH != "amd64" && runtime.GOARCH != "loong64" && runtime.GOARCH != "ppc64le" && runtime.GOARCH != "riscv64" && runtime.GOARCH != "s390x" {
+		t.Skip("Platform doesn't support Floats")
+		return
+	}
+	if runtime.GOOS == "windows" && runtime.GOARCH == "386" {
+		t.Skip("need a 32bit gcc to run this test") // TODO: find 32bit gcc for test
+	}
+	library, err := getSystemLibrary()
+	if err != nil {
+		t.Fatalf("couldn't get system library: %s", err)
+	}
+	libc, err := load.OpenLibrary(library)
+	if err != nil {
+		t.Fatalf("failed to dlopen: %s", err)
+	}
+	sym := func(name string) uintptr {
+		t.Helper()
+		fn, err := load.OpenSymbol(libc, name)
+		if err != nil {
+			t.Fatalf("failed to find %s: %s", name, err)
+		}
+		return fn
+	}
+
+	strlen := purego.NewFunc1[uintptr, string](sym("strlen"))
+	if got := strlen("purego"); got != 6 {
+		t.Errorf("strlen failed. got %d but wanted %d", got, 6)
+	}
+	abs := purego.NewFunc1[int32, int32](sym("abs"))
+	if got := abs(-42); got != 42 {
+		t.Errorf("abs failed. got %d but wanted %d", got, 42)
+	}
+	strtod := purego.NewFunc2[float64, string, **byte](sym("strtod"))
+	if got := strtod("1.5", nil); got != 1.5 {
+		t.Errorf("strtod failed. got %f but wanted %f", got, 1.5)
+	}
+	strtof := purego.NewFunc2[float32, string, **byte](sym("strtof"))
+	if got := strtof("2.5", nil); got != 2.5 {
+		t.Errorf("strtof failed. got %f but wanted %f", got, 2.5)
+	}
+
+	var called bool
+	cb := purego.NewCallback(func(a int8, b uint16, c float64, d bool, e *int32, f int64, g float32, h uintptr) int64 {
+		called = true
+		if !d {
+			return -1
+		}
+		return int64(a) + int64(b) + int64(c) + int64(*e) + f + int64(g) + int64(h)
+	})
+	mixed := purego.NewFunc8[int64, int8, uint16, float64, bool, *int32, int64, float32, uintptr](cb)
+	e := int32(5)
+	if got := mixed(-1, 2, 3, true, &e, 6, 7, 8); got != 30 {
+		t.Errorf("mixed failed. got %d but wanted %d", got, 30)
+	}
+	if !called {
+		t.Errorf("callback was not called")
+	}
+
+	called = false
+	void := purego.NewFunc0[struct{}](purego.NewCallback(func() { called = true }))
+	void()
+	if !called {
+		t.Errorf("void callback was not called")
+	}
+}
+
+func TestNewFunc_Unsupported(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("NewFunc1 accepted a struct argument")
+		}
+	}()
+	purego.NewFunc1[int, struct{ a, b int }](1)
+}
+
+func TestRegisterFunc_Cgocheck(t *testing.T) {
+	library, err := getSystemLibrary()
+	if err != nil {
+		t.Fatalf("couldn't get system library: %s", err)
+	}
+	libc, err := load.OpenLibrary(library)
+	if err != nil {
+		t.Fatalf("failed to dlopen: %s", err)
+	}
+	// abs ignores what the pointers point to. Only the checks done before the call matter.
+	abs, err := load.OpenSymbol(libc, "abs")
+	if err != nil {
+		t.Fatalf("failed to find abs: %s", err)
+	}
+
+	type node struct{ next *node }
+	type holder struct{ n *node }
+	var absNode func(n *node) int32
+	purego.RegisterFunc(&absNode, abs)
+	var absSlice func(s []*node) int32
+	purego.RegisterFunc(&absSlice, abs)
+	var absAny func(args ...any) int32
+	purego.RegisterFunc(&absAny, abs)
+	var absPinned func(n purego.Pinned[node]) int32
+	purego.RegisterFunc(&absPinned, abs)
+	absTyped := purego.NewFunc1[int32, *node](abs)
+
+	pinnedNext := purego.Pin(&node{})
+	defer pinnedNext.Unpin()
+	pinned := purego.Pin(&node{next: pinnedNext.Pointer()})
+	defer pinned.Unpin()
+	unpinnedNext := purego.Pin(&node{next: &node{}})
+	defer unpinnedNext.Unpin()
+
+	tests := []struct {
+		name  string
+		call  func()
+		panic bool
+	}{
+		{"Pointer", func() { absNode(&node{next: &node{}}) }, true},
+		{"PointerWithoutPointers", func() { absNode(&node{}) }, false},
+		{"PointerToPinned", func() { absNode(&node{next: pinnedNext.Pointer()}) }, false},
+		{"Slice", func() { absSlice([]*node{{}}) }, true},
+		{"Any", func() { absAny(&node{next: &node{}}) }, true},
+		{"NewFunc", func() { absTyped(&node{next: &node{}}) }, true},
+		{"Pinned", func() { absPinned(pinned) }, false},
+		{"PinnedToUnpinned", func() { absPinned(unpinnedNext) }, true},
+	}
+	for _, enabled := range []bool{false, true} {
+		old := purego.SetDebugCgocheck(enabled)
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/%t", tt.name, enabled), func(t *testing.T) {
+				defer func() {
+					r := recover()
+					if want := tt.panic && enabled; (r != nil) != want {
+						t.Fatalf("panic = %v, want a panic: %t", r, want)
+					}
+					if msg, _ := r.(string); r != nil && !strings.HasPrefix(msg, "purego: argument 0 of type ") {
+						t.Errorf("unexpected panic message %q", msg)
+					}
+				}()
+				tt.call()
+			})
+		}
+		purego.SetDebugCgocheck(old)
+	}
+}
+
+func TestRegisterFunc_Errno(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("C errno is not reported by GetLastError on Windows")
+	}
+	library, err := getSystemLibrary()
+	if err != nil {
+		t.Fatalf("couldn't get system library: %s", err)
+	}
+	libc, err := load.OpenLibrary(library)
+	if err != nil {
+		t.Fatalf("failed to dlopen: %s", err)
+	}
+
+	var open func(path string, flags int32) (int32, syscall.Errno)
+	purego.RegisterLibFunc(&open, libc, "open")
+	if fd, errno := open("_file_that_does_not_exist_", int32(os.O_RDONLY)); fd != -1 || errno != syscall.ENOENT {
+		t.Errorf("open returned (%d, %v), wanted (-1, %v)", fd, errno, syscall.ENOENT)
+	}
+
+	var openErr func(path string, flags int32) (int32, error)
+	purego.RegisterLibFunc(&openErr, libc, "open")
+	if _, err := openErr("_file_that_does_not_exist_", int32(os.O_RDONLY)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("open returned %v, wanted %v", err, syscall.ENOENT)
+	}
+
+	// errno is cleared before each call so a function that doesn't fail reports no error.
+	var strlen func(string) (int, error)
+	purego.RegisterLibFunc(&strlen, libc, "strlen")
+	if n, err := strlen("purego"); n != 6 || err != nil {
+		t.Errorf("strlen returned (%d, %v), wanted (6, <nil>)", n, err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("RegisterLibFunc accepted a second return value that is not errno")
+		}
+	}()
+	var bad func() (int32, int32)
+	purego.RegisterLibFunc(&bad, libc, "getpid")
+}
+
+func TestTryRegisterFunc(t *testing.T) {
+	library, err := getSystemLibrary()
+	if err != nil {
+		t.Fatalf("couldn't get system library: %s", err)
+	}
+	libc, err := load.OpenLibrary(library)
+	if err != nil {
+		t.Fatalf("failed to dlopen: %s", err)
+	}
+
+	var strlen func(string) uintptr
+	if err := purego.TryRegisterLibFunc(&strlen, libc, "strlen"); err != nil {
+		t.Fatalf("TryRegisterLibFunc failed: %v", err)
+	}
+	if got := strlen("purego"); got != 6 {
+		t.Errorf("strlen failed. got %d but wanted %d", got, 6)
+	}
+
+	var missing func()
+	err = purego.TryRegisterLibFunc(&missing, libc, "_symbol_that_does_not_exist_")
+	var symErr *purego.SymbolNotFoundError
+	if !errors.As(err, &symErr) || symErr.Name != "_symbol_that_does_not_exist_" {
+		t.Errorf("TryRegisterLibFunc returned %v, wanted a *SymbolNotFoundError", err)
+	}
+	if missing != nil {
+		t.Errorf("TryRegisterLibFunc set the function on error")
+	}
+
+	var badArg func(int, chan int)
+	err = purego.TryRegisterFunc(&badArg, 1)
+	var typeErr *purego.UnsupportedTypeError
+	if !errors.As(err, &typeErr) || typeErr.Arg != 1 || typeErr.Type != reflect.TypeFor[chan int]() {
+		t.Errorf("TryRegisterFunc returned %v, wanted an *UnsupportedTypeError for argument 1", err)
+	}
+	if badArg != nil {
+		t.Errorf("TryRegisterFunc set the function on error")
+	}
+
+	var badReturn func() map[int]int
+	err = purego.TryRegisterFunc(&badReturn, 1)
+	if !errors.As(err, &typeErr) || typeErr.Arg != -1 || typeErr.Type != reflect.TypeFor[map[int]int]() {
+		t.Errorf("TryRegisterFunc returned %v, wanted an *UnsupportedTypeError for the return value", err)
+	}
+
+	var tooMany func(
+		int64, int64, int64, int64, int64, int64, int64, int64,
+		int64, int64, int64, int64, int64, int64, int64, int64,
+		int64, int64, int64, int64, int64, int64, int64, int64,
+		int64, int64, int64, int64, int64, int64, int64, int64,
+		int64,
+	)
+	if err := purego.TryRegisterFunc(&tooMany, 1); !errors.Is(err, purego.ErrTooManyArguments) {
+		t.Errorf("TryRegisterFunc returned %v, wanted %v", err, purego.ErrTooManyArguments)
+	}
+}
+
+func TestRegisterVariadicFunc(t *testing.T) {
+	if runtime.GOARCH != "arm" && runtime.GOARCH != "arm64" && runtime.GOARCH != "386" && runtime.GOARCH != "amd64" && runtime.GOARCH != "loong64" && runtime.GOARCH != "ppc64le" && runtime.GOARCH != "riscv64" && runtime.GOARCH != "s390x" {
+		t.Skip("Platform doesn't support Floats")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("snprintf is not exported by ucrtbase.dll")
+	}
+	library, err := getSystemLibrary()
+	if err != nil {
+		t.Fatalf("couldn't get system library: %s", err)
+	}
+	libc, err := load.OpenLibrary(library)
+	if err != nil {
+		t.Fatalf("failed to dlopen: %s", err)
+	}
+	snprintfSym, err := load.OpenSymbol(libc, "snprintf")
+	if err != nil {
+		t.Fatalf("failed to find snprintf: %s", err)
+	}
+
+	var snprintf func(buf []byte, size uintptr, format string, args ...any) int32
+	purego.RegisterVariadicFunc(&snprintf, snprintfSym, 3)
+	buf := make([]byte, 64)
+	n := snprintf(buf, uintptr(len(buf)), "%d %.2f %s %.1f %c", int32(42), 3.14159, "purego", float32(2.5), 'x')
+	const want = "42 3.14 purego 2.5 x"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("snprintf wrote %q, wanted %q", got, want)
+	}
+
+	// Named Go arguments after fixedArgs are variadic too.
+	var snprintf2 func(buf []byte, size uintptr, format string, a float64, b int64, c float64) int32
+	purego.RegisterVariadicFunc(&snprintf2, snprintfSym, 3)
+	n = snprintf2(buf, uintptr(len(buf)), "%.1f %lld %.1f", 1.5, 1<<40, -0.5)
+	const want2 = "1.5 1099511627776 -0.5"
+	if got := string(buf[:n]); got != want2 {
+		t.Errorf("snprintf wrote %q, wanted %q", got, want2)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("RegisterVariadicFunc accepted more fixed arguments than the function has")
+		}
+	}()
+	purego.RegisterVariadicFunc(&snprintf, snprintfSym, 4)
+}
+
 func TestRegisterLibFunc_Bool(t *testing.T) {
 	if runtime.GOARCH != "arm" && runtime.GOARCH != "arm64" && runtime.GOARCH != "386" && runtime.GOARCH != "amd64" && runtime.GOARCH != "loong64" && runtime.GOARCH != "ppc64le" && runtime.GOARCH != "riscv64" && runtime.GOARCH != "s390x" {
 		t.Skip("Platform doesn't support callbacks")
@@ -237,6 +525,22 @@ func TestABI(t *testing.T) {
 			t.Fatalf("%s: got %q, want %q", cName, res, want)
 		}
 	}
+	{
+		const cName = "variadic_fixed_floats"
+		var fn func(*byte, uintptr, float32, float64, int32, ...any)
+		sym, err := load.OpenSymbol(lib, cName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		purego.RegisterVariadicFunc(&fn, sym, 5)
+		buf := make([]byte, 256)
+		fn(&buf[0], uintptr(len(buf)), 1.5, 2.5, 2, 3.5, float32(4.5))
+		res := string(buf[:bytes.IndexByte(buf, 0)])
+		const want = "1.5:2.5:3.5:4.5"
+		if res != want {
+			t.Fatalf("%s: got %q, want %q", cName, res, want)
+		}
+	}
 }
 
 func TestABI_ArgumentPassing(t *testing.T) {
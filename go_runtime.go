@@ -11,3 +11,9 @@ import (
 
 //go:linkname runtime_cgocall runtime.cgocall
 func runtime_cgocall(fn uintptr, arg unsafe.Pointer) int32 // from runtime/sys_libc.go
+
+//go:linkname runtime_cgoCheckPointer runtime.cgoCheckPointer
+func runtime_cgoCheckPointer(ptr any, arg any) // from runtime/cgocall.go
+
+//go:linkname runtime_throw runtime.throw
+func runtime_throw(s string) // from runtime/panic.go
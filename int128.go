@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+)
+
+// Int128 is a C __int128 and Uint128 is a C unsigned __int128. They are passed and returned like
+// the C types, either directly or as a field of a struct, and hold the low and high 64 bits of the
+// two's complement value. They are converted to and from Go numbers with [NewInt128], [NewUint128],
+// [NewInt128FromBigInt], [NewUint128FromBigInt], [Int128.BigInt] and [Uint128.BigInt].
+//
+// Int128 and Uint128 are supported on amd64, arm64 and riscv64 except on Windows, where the C types
+// do not exist. C aligns them to 16 bytes while Go aligns them to 8 bytes, so a struct field may need
+// explicit padding, which [CheckLayout] reports. They are not supported in unions or ...any arguments,
+// and are only supported in callbacks as an argument by themselves, not as a field of a struct.
+type Int128 struct {
+	Lo uint64
+	Hi int64
+}
+
+// Uint128 is a C unsigned __int128, see [Int128].
+type Uint128 struct {
+	Lo, Hi uint64
+}
+
+// NewInt128 returns x as an Int128.
+func NewInt128(x int64) Int128 {
+	return Int128{Lo: uint64(x), Hi: x >> 63}
+}
+
+// NewUint128 returns x as a Uint128.
+func NewUint128(x uint64) Uint128 {
+	return Uint128{Lo: x}
+}
+
+// NewInt128FromBigInt returns the low 128 bits of the two's complement of x as an Int128.
+func NewInt128FromBigInt(x *big.Int) Int128 {
+	lo, hi := low128(x)
+	return Int128{Lo: lo, Hi: int64(hi)}
+}
+
+// NewUint128FromBigInt returns the low 128 bits of the two's complement of x as a Uint128.
+func NewUint128FromBigInt(x *big.Int) Uint128 {
+	lo, hi := low128(x)
+	return Uint128{Lo: lo, Hi: hi}
+}
+
+// low128 returns the low and high 64 bits of the two's complement of x.
+func low128(x *big.Int) (lo, hi uint64) {
+	mask := new(big.Int).SetUint64(math.MaxUint64)
+	// And treats a negative number as its infinite two's complement.
+	lo = new(big.Int).And(x, mask).Uint64()
+	hi = new(big.Int).And(new(big.Int).Rsh(x, 64), mask).Uint64()
+	return lo, hi
+}
+
+// BigInt returns i as a *big.Int.
+func (i Int128) BigInt() *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(i.Hi), 64)
+	return x.Add(x, new(big.Int).SetUint64(i.Lo))
+}
+
+// BigInt returns u as a *big.Int.
+func (u Uint128) BigInt() *big.Int {
+	x := new(big.Int).Lsh(new(big.Int).SetUint64(u.Hi), 64)
+	return x.Add(x, new(big.Int).SetUint64(u.Lo))
+}
+
+// isInt128Type reports whether t is Int128 or Uint128.
+func isInt128Type(t reflect.Type) bool {
+	return t == reflect.TypeFor[Int128]() || t == reflect.TypeFor[Uint128]()
+}
+
+// containsInt128 reports whether t is or contains an Int128 or a Uint128.
+func containsInt128(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		if isInt128Type(t) {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if containsInt128(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return containsInt128(t.Elem())
+	}
+	return false
+}
+
+// ensureInt128Supported panics if __int128 cannot be passed to or returned from C
+// on the current platform.
+func ensureInt128Supported() {
+	if runtime.GOOS == "windows" {
+		panic("purego: Int128 and Uint128 are not supported on windows")
+	}
+	switch runtime.GOARCH {
+	case "amd64", "arm64", "riscv64":
+	default:
+		panic("purego: Int128 and Uint128 are only supported on amd64, arm64 and riscv64")
+	}
+}
+
+// addInt128 passes v if it is an Int128, a Uint128 or a struct holding one that is not passed
+// by reference, and reports whether it did. On darwin/arm64 it leaves a value that no longer fits
+// in the integer registers to the stack argument bundling, which aligns it like __int128.
+func addInt128(v reflect.Value, addInt, addStack func(uintptr), numInts, numStack *int) bool {
+	if !containsInt128(v.Type()) {
+		return false
+	}
+	switch runtime.GOARCH {
+	case "amd64":
+		// Two INTEGER eightbytes are passed in registers only if both fit.
+		// Otherwise the value is passed in memory, which is aligned to 16 bytes like __int128.
+		if v.Type().Size() <= 16 && *numInts+2 <= numOfIntegerRegisters() {
+			addWords(v, addInt)
+			return true
+		}
+		if *numStack%2 != 0 {
+			addStack(0)
+		}
+		addWords(v, addStack)
+		return true
+	case "arm64":
+		// A larger struct is passed by reference.
+		if v.Type().Size() != 16 {
+			return false
+		}
+		// A value aligned to 16 bytes starts at an even-numbered register.
+		if *numInts%2 != 0 && *numInts < numOfIntegerRegisters() {
+			addInt(0)
+		}
+		if *numInts+2 <= numOfIntegerRegisters() {
+			addWords(v, addInt)
+			return true
+		}
+		if isDarwin {
+			return false
+		}
+		// The whole value goes on the stack and no later argument uses the integer registers.
+		*numInts = numOfIntegerRegisters()
+		if *numStack%2 != 0 {
+			addStack(0)
+		}
+		addWords(v, addStack)
+		return true
+	case "riscv64":
+		if !isInt128Type(v.Type()) {
+			return false
+		}
+		addRegisterPair(v, addInt, addStack, numInts, numStack)
+		return true
+	}
+	return false
+}
+
+// addRegisterPair passes the 16-byte value v like a scalar twice the size of a register on riscv64:
+// in a pair of integer registers, in the last one and on the stack, or wholly on the stack
+// aligned to 16 bytes.
+func addRegisterPair(v reflect.Value, addInt, addStack func(uintptr), numInts, numStack *int) {
+	if *numInts >= numOfIntegerRegisters() && *numStack%2 != 0 {
+		addStack(0)
+	}
+	addWords(v, addInt)
+}
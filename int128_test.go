@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || linux) && (amd64 || arm64)
+
+package purego_test
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+func openABITest(t *testing.T) uintptr {
+	t.Helper()
+	libFileName := filepath.Join(t.TempDir(), "abitest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "abitest", "abi_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+// bigInt returns the integer s in base 0.
+func bigInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic("invalid integer " + s)
+	}
+	return x
+}
+
+func TestInt128_Conversion(t *testing.T) {
+	for _, x := range []int64{0, 1, -1, math.MaxInt64, math.MinInt64} {
+		if got := purego.NewInt128(x).BigInt(); got.Cmp(big.NewInt(x)) != 0 {
+			t.Errorf("NewInt128(%d).BigInt() = %v", x, got)
+		}
+	}
+	if got := purego.NewUint128(math.MaxUint64).BigInt(); got.Cmp(new(big.Int).SetUint64(math.MaxUint64)) != 0 {
+		t.Errorf("NewUint128(MaxUint64).BigInt() = %v", got)
+	}
+	for _, s := range []string{"0x7fffffffffffffffffffffffffffffff", "-0x80000000000000000000000000000000", "-0x123456789abcdef0123456789"} {
+		x := bigInt(s)
+		if got := purego.NewInt128FromBigInt(x).BigInt(); got.Cmp(x) != 0 {
+			t.Errorf("NewInt128FromBigInt(%v).BigInt() = %v", x, got)
+		}
+	}
+	if got, want := purego.NewInt128FromBigInt(big.NewInt(-2)), (purego.Int128{Lo: math.MaxUint64 - 1, Hi: -1}); got != want {
+		t.Errorf("NewInt128FromBigInt(-2) = %+v, want %+v", got, want)
+	}
+	if got, want := purego.NewUint128FromBigInt(big.NewInt(-1)), (purego.Uint128{Lo: math.MaxUint64, Hi: math.MaxUint64}); got != want {
+		t.Errorf("NewUint128FromBigInt(-1) = %+v, want %+v", got, want)
+	}
+	// Only the low 128 bits are kept.
+	if got := purego.NewUint128FromBigInt(bigInt("0x1_00000000000000000000000000000005")).BigInt(); got.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("NewUint128FromBigInt(2**128 + 5).BigInt() = %v, want 5", got)
+	}
+}
+
+type (
+	box128 struct {
+		Value purego.Int128
+	}
+	tagged128 struct {
+		Tag   int32
+		_     [12]byte
+		Value purego.Int128
+	}
+)
+
+func TestInt128(t *testing.T) {
+	lib := openABITest(t)
+
+	a := bigInt("0x0123456789abcdeffedcba9876543210")
+	b := bigInt("-0x76543210fedcba98")
+	want := func(x *big.Int, n int64) *big.Int {
+		return new(big.Int).Add(x, big.NewInt(n))
+	}
+	check := func(name string, got purego.Int128, want *big.Int) {
+		t.Helper()
+		if got := got.BigInt(); got.Cmp(want) != 0 {
+			t.Errorf("%s() = %#x, want %#x", name, got, want)
+		}
+	}
+
+	var int128Add func(a, b purego.Int128) purego.Int128
+	purego.RegisterLibFunc(&int128Add, lib, "int128_add")
+	check("int128_add", int128Add(purego.NewInt128FromBigInt(a), purego.NewInt128FromBigInt(b)), new(big.Int).Add(a, b))
+
+	var uint128Mul func(a, b purego.Uint128) purego.Uint128
+	purego.RegisterLibFunc(&uint128Mul, lib, "uint128_mul")
+	if got := uint128Mul(purego.NewUint128(math.MaxUint64), purego.NewUint128(math.MaxUint64)).BigInt(); got.Cmp(bigInt("0xfffffffffffffffe0000000000000001")) != 0 {
+		t.Errorf("uint128_mul() = %#x, want 0xfffffffffffffffe0000000000000001", got)
+	}
+
+	x := purego.NewInt128FromBigInt(a)
+
+	var afterOdd func(a int64, b purego.Int128) purego.Int128
+	purego.RegisterLibFunc(&afterOdd, lib, "int128_after_odd")
+	check("int128_after_odd", afterOdd(3, x), want(a, -3))
+
+	var oneRegLeft func(a1, a2, a3, a4, a5 int64, x purego.Int128, b int64) purego.Int128
+	purego.RegisterLibFunc(&oneRegLeft, lib, "int128_one_reg_left")
+	check("int128_one_reg_left", oneRegLeft(1, 2, 3, 4, 5, x, 100), want(a, 100-15))
+
+	var stackAfterOdd func(a1, a2, a3, a4, a5, a6, a7, a8, s int64, x purego.Int128) purego.Int128
+	purego.RegisterLibFunc(&stackAfterOdd, lib, "int128_stack_after_odd")
+	check("int128_stack_after_odd", stackAfterOdd(1, 2, 3, 4, 5, 6, 7, 8, 100, x), want(a, 100-36))
+
+	var afterOddStack func(a1, a2, a3, a4, a5, a6, a7 int64, x purego.Int128, b int64) purego.Int128
+	purego.RegisterLibFunc(&afterOddStack, lib, "int128_after_odd_stack")
+	check("int128_after_odd_stack", afterOddStack(1, 2, 3, 4, 5, 6, 7, x, 100), want(a, 100-28))
+
+	var box128AfterOdd func(a int32, b box128) purego.Int128
+	purego.RegisterLibFunc(&box128AfterOdd, lib, "box128_after_odd")
+	check("box128_after_odd", box128AfterOdd(3, box128{x}), want(a, -3))
+
+	if err := purego.CheckLayout(reflect.TypeFor[tagged128](), nil); err != nil {
+		t.Errorf("CheckLayout() = %v", err)
+	}
+	var tagged128Value func(a1, a2, a3, a4, a5, a6, a7 int64, t tagged128) purego.Int128
+	purego.RegisterLibFunc(&tagged128Value, lib, "tagged128_value")
+	check("tagged128_value", tagged128Value(1, 2, 3, 4, 5, 6, 7, tagged128{Tag: 100, Value: x}), want(a, 100-28))
+	var makeTagged128 func(tag int32, value purego.Int128) tagged128
+	purego.RegisterLibFunc(&makeTagged128, lib, "make_tagged128")
+	if tg := makeTagged128(3, x); tg.Tag != 3 || tg.Value != x {
+		t.Errorf("make_tagged128() = {%d, %+v}, want {3, %+v}", tg.Tag, tg.Value, x)
+	}
+}
+
+func TestInt128Callback(t *testing.T) {
+	lib := openABITest(t)
+
+	a := bigInt("-0x0123456789abcdeffedcba9876543210")
+	x := purego.NewInt128FromBigInt(a)
+
+	var int128Callback func(cb func(a int64, b purego.Int128) purego.Int128, a int64, b purego.Int128) purego.Int128
+	purego.RegisterLibFunc(&int128Callback, lib, "int128_callback")
+	got := int128Callback(func(a int64, b purego.Int128) purego.Int128 {
+		return purego.NewInt128FromBigInt(new(big.Int).Sub(b.BigInt(), big.NewInt(a)))
+	}, 3, x)
+	if want := new(big.Int).Sub(a, big.NewInt(3)); got.BigInt().Cmp(want) != 0 {
+		t.Errorf("int128_callback() = %#x, want %#x", got.BigInt(), want)
+	}
+
+	var int128CallbackStack func(cb func(a1, a2, a3, a4, a5, a6, a7 int64, x purego.Int128) purego.Int128, x purego.Int128) purego.Int128
+	purego.RegisterLibFunc(&int128CallbackStack, lib, "int128_callback_stack")
+	got = int128CallbackStack(func(a1, a2, a3, a4, a5, a6, a7 int64, x purego.Int128) purego.Int128 {
+		if a1 != 1 || a2 != 2 || a3 != 3 || a4 != 4 || a5 != 5 || a6 != 6 || a7 != 7 {
+			t.Errorf("callback got %d %d %d %d %d %d %d, want 1 2 3 4 5 6 7", a1, a2, a3, a4, a5, a6, a7)
+		}
+		return x
+	}, x)
+	if got != x {
+		t.Errorf("int128_callback_stack() = %+v, want %+v", got, x)
+	}
+}
+
+func TestInt128_Unsupported(t *testing.T) {
+	var fn func(x ...any)
+	purego.RegisterFunc(&fn, 1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("passing an Int128 in ...any did not panic")
+			}
+		}()
+		fn(purego.NewInt128(1))
+	}()
+	defer func() {
+		if recover() == nil {
+			t.Error("NewCallback with a struct holding an Int128 did not panic")
+		}
+	}()
+	purego.NewCallback(func(b box128) {})
+}
@@ -68,6 +68,12 @@ var _set_crosscall2 = set_crosscall2
 var x_cgo_bindm_trampoline byte
 var _cgo_bindm = &x_cgo_bindm_trampoline
 
+// The destructor of the pthread key that holds the g bound to a C thread.
+// It calls crosscall2 with a nil fn, which makes cgocallback dropm.
+
+//go:linkname pthread_key_destructor_trampoline pthread_key_destructor_trampoline
+var pthread_key_destructor_trampoline byte
+
 // TODO: decide if we need x_cgo_set_context_function
 // TODO: decide if we need _cgo_yield
 
@@ -90,4 +96,5 @@ var (
 	x_cgo_setenv_call       = x_cgo_setenv
 	x_cgo_unsetenv_call     = x_cgo_unsetenv
 	x_cgo_thread_start_call = x_cgo_thread_start
+	x_cgo_bindm_call        = x_cgo_bindm
 )
@@ -146,6 +146,7 @@ var (
 		{"pthread_mutex_unlock", [5]Arg{{"mutex", "*pthread_mutex_t"}}, "int32", nil},
 		{"pthread_cond_broadcast", [5]Arg{{"cond", "*pthread_cond_t"}}, "int32", nil},
 		{"pthread_setspecific", [5]Arg{{"key", "pthread_key_t"}, {"value", "unsafe.Pointer"}}, "int32", nil},
+		{"pthread_key_create", [5]Arg{{"key", "*pthread_key_t"}, {"destructor", "unsafe.Pointer"}}, "int32", nil},
 	}
 )
 
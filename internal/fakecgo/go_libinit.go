@@ -21,6 +21,14 @@ var (
 //go:nosplit
 //go:norace
 func x_cgo_notify_runtime_init_done() {
+	// The key and x_cgo_pthread_key_created are for the whole program,
+	// whereas the specific value and the destructor are per thread.
+	// Once the key is created, the runtime binds the extra M used by a C thread
+	// to that thread with x_cgo_bindm instead of dropping it after every callback.
+	if x_cgo_pthread_key_created == 0 && pthread_key_create(&pthread_g, unsafe.Pointer(&pthread_key_destructor_trampoline)) == 0 {
+		x_cgo_pthread_key_created = 1
+	}
+
 	pthread_mutex_lock(&runtime_init_mu)
 	runtime_init_done = 1
 	pthread_cond_broadcast(&runtime_init_cond)
@@ -30,6 +38,7 @@ func x_cgo_notify_runtime_init_done() {
 // Store the g into a thread-specific value associated with the pthread key pthread_g.
 // And pthread_key_destructor will dropm when the thread is exiting.
 //
+//go:nosplit
 //go:norace
 func x_cgo_bindm(g unsafe.Pointer) {
 	// We assume this will always succeed, otherwise, there might be extra M leaking,
@@ -14,7 +14,6 @@ type (
 	sigset_t       [128]byte
 	pthread_attr_t [64]byte
 	pthread_t      int
-	pthread_key_t  uint64
 )
 
 // for pthread_sigmask:
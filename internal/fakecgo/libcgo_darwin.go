@@ -14,6 +14,7 @@ type (
 		sig    int64
 		opaque [40]byte
 	}
+	pthread_key_t uint64
 )
 
 var (
@@ -8,6 +8,7 @@ package fakecgo
 type (
 	pthread_cond_t  uintptr
 	pthread_mutex_t uintptr
+	pthread_key_t   int32
 )
 
 var (
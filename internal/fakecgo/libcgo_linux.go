@@ -8,6 +8,7 @@ package fakecgo
 type (
 	pthread_cond_t  [48]byte
 	pthread_mutex_t [48]byte
+	pthread_key_t   uint32
 )
 
 var (
@@ -65,8 +65,22 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0-0
 	CALL ·x_cgo_notify_runtime_init_done(SB)
 	RET
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	CALL ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $4-0
+	MOVL 8(SP), AX                 // first C arg
+	MOVL AX, 0(SP)                 // Go arg 1
+	MOVL ·x_cgo_bindm_call(SB), CX
+	MOVL (CX), CX
+	CALL CX
+	RET
+
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT, $16-0
+	MOVL 20(SP), AX // first C arg
+	MOVL $0, 0(SP)  // fn
+	MOVL AX, 4(SP)  // a
+	MOVL $0, 8(SP)  // n
+	MOVL $0, 12(SP) // ctxt
+	CALL crosscall2(SB)
 	RET
 
 // func setg_trampoline(setg uintptr, g uintptr)
@@ -56,8 +56,20 @@ TEXT x_cgo_unsetenv_trampoline(SB), NOSPLIT, $8
 TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0
 	JMP ·x_cgo_notify_runtime_init_done(SB)
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	JMP ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $8
+	MOVQ DI, AX
+	MOVQ ·x_cgo_bindm_call(SB), R11
+	MOVQ (R11), R11
+	CALL R11
+	RET
+
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVQ DI, SI
+	XORL DI, DI
+	XORL DX, DX
+	XORL CX, CX
+	JMP  crosscall2(SB)
 
 // func setg_trampoline(setg uintptr, g uintptr)
 TEXT ·setg_trampoline(SB), NOSPLIT, $0-16
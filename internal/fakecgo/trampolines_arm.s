@@ -43,10 +43,21 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0-0
 	CALL ·x_cgo_notify_runtime_init_done(SB)
 	RET
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	CALL ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $8-0
+	MOVW R0, 4(R13)
+	MOVW ·x_cgo_bindm_call(SB), R12
+	MOVW (R12), R12
+	CALL (R12)
 	RET
 
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVW R0, R1
+	MOVW $0, R0
+	MOVW $0, R2
+	MOVW $0, R3
+	B    crosscall2(SB)
+
 // func setg_trampoline(setg uintptr, g uintptr)
 TEXT ·setg_trampoline(SB), NOSPLIT, $0-8
 	MOVW G+4(FP), R0
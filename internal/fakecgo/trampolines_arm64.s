@@ -39,10 +39,20 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0-0
 	CALL ·x_cgo_notify_runtime_init_done(SB)
 	RET
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	CALL ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0-0
+	MOVD ·x_cgo_bindm_call(SB), R9
+	MOVD (R9), R9
+	CALL R9
 	RET
 
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVD R0, R1
+	MOVD $0, R0
+	MOVD $0, R2
+	MOVD $0, R3
+	B    crosscall2(SB)
+
 // func setg_trampoline(setg uintptr, g uintptr)
 TEXT ·setg_trampoline(SB), NOSPLIT, $0-16
 	MOVD G+8(FP), R0
@@ -38,10 +38,20 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0
 	CALL ·x_cgo_notify_runtime_init_done(SB)
 	RET
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	CALL ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $8
+	MOVV ·x_cgo_bindm_call(SB), R23
+	MOVV (R23), R23
+	CALL (R23)
 	RET
 
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVV R4, R5
+	MOVV R0, R4
+	MOVV R0, R6
+	MOVV R0, R7
+	JMP  crosscall2(SB)
+
 // func setg_trampoline(setg uintptr, g uintptr)
 TEXT ·setg_trampoline(SB), NOSPLIT, $0
 	MOVV G+8(FP), R4
@@ -42,15 +42,26 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0-0
 	RET
 
 TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0-0
-	CALL ·x_cgo_bindm(SB)
+	MOVD ·x_cgo_bindm_call(SB), R12
+	MOVD (R12), R12
+	MOVD R12, CTR
+	CALL CTR
 	RET
 
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVD R3, R4
+	MOVD $0, R3
+	MOVD $0, R5
+	MOVD $0, R6
+	BR   crosscall2(SB)
+
 // func setg_trampoline(setg uintptr, g uintptr)
 TEXT ·setg_trampoline(SB), NOSPLIT, $16-16
 	MOVD R31, 8(R1) // save R31
 
 	MOVD setg+0(FP), R12
-	MOVD newg+8(FP), R3
+	MOVD G+8(FP), R3
 
 	MOVD R3, 16(R1) // save newg before call
 
@@ -101,26 +112,24 @@ TEXT ·call5(SB), NOSPLIT|NOFRAME, $0-56
 	MOVW CR, R21
 
 	// Load arguments from Go stack into C argument registers
-	// Go placed args at 32(R1), 40(R1), etc.
-	MOVD 32(R1), R12 // fn
-	MOVD 40(R1), R3  // a1 → first C arg
-	MOVD 48(R1), R4  // a2 → second C arg
-	MOVD 56(R1), R5  // a3 → third C arg
-	MOVD 64(R1), R6  // a4 → fourth C arg
-	MOVD 72(R1), R7  // a5 → fifth C arg
+	MOVD fn+0(FP), R12
+	MOVD a1+8(FP), R3  // first C arg
+	MOVD a2+16(FP), R4 // second C arg
+	MOVD a3+24(FP), R5 // third C arg
+	MOVD a4+32(FP), R6 // fourth C arg
+	MOVD a5+40(FP), R7 // fifth C arg
 
 	MOVDU R1, -32(R1)
 
 	MOVD R12, CTR
 	CALL CTR
 
-	// Store return value
-	// After MOVDU -32, original 80(R1) is now at 80+32=112(R1)
-	MOVD R3, (80+32)(R1)
-
 	// Deallocate frame
 	ADD $32, R1
 
+	// Store return value now that R1 points to the Go frame again
+	MOVD R3, ret+48(FP)
+
 	// Clear R0 before returning to Go code.
 	// Go uses R0 as a constant 0 register for things like "std r0,X(r1)"
 	// to zero stack locations. C functions may leave garbage in R0.
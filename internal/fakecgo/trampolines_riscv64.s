@@ -38,13 +38,23 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT, $0
 	CALL ·x_cgo_notify_runtime_init_done(SB)
 	RET
 
-TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $0
-	CALL ·x_cgo_bindm(SB)
+TEXT x_cgo_bindm_trampoline(SB), NOSPLIT, $8
+	MOV  ·x_cgo_bindm_call(SB), X5
+	MOV  (X5), X5
+	CALL X5
 	RET
 
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOV X10, X11
+	MOV X0, X10
+	MOV X0, X12
+	MOV X0, X13
+	JMP crosscall2(SB)
+
 // func setg_trampoline(setg uintptr, g uintptr)
-TEXT ·setg_trampoline(SB), NOSPLIT, $0
-	MOV  gp+8(FP), X10
+TEXT ·setg_trampoline(SB), NOSPLIT, $0-16
+	MOV  G+8(FP), X10
 	MOV  setg+0(FP), X5
 	CALL X5
 	RET
@@ -64,7 +74,7 @@ TEXT threadentry_trampoline(SB), NOSPLIT, $200
 
 	RET
 
-TEXT ·call5(SB), NOSPLIT, $0-48
+TEXT ·call5(SB), NOSPLIT, $0-56
 	MOV  fn+0(FP), X5
 	MOV  a1+8(FP), X10
 	MOV  a2+16(FP), X11
@@ -73,7 +73,26 @@ TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT|NOFRAME, $0-0
 	BR ·x_cgo_notify_runtime_init_done(SB)
 
 TEXT x_cgo_bindm_trampoline(SB), NOSPLIT|NOFRAME, $0-0
-	BR ·x_cgo_bindm(SB)
+	MOVD R15, R1
+	SUB  $176, R15
+	MOVD R1, 0(R15)    // backchain
+	MOVD R14, 152(R15) // save R14
+
+	MOVD ·x_cgo_bindm_call(SB), R1
+	MOVD (R1), R1
+	BL   R1
+
+	MOVD 152(R15), R14
+	ADD  $176, R15
+	BR   R14
+
+// pthread_key_destructor_trampoline(g) calls crosscall2(nil, g, 0, 0) to dropm.
+TEXT pthread_key_destructor_trampoline(SB), NOSPLIT|NOFRAME, $0
+	MOVD R2, R3
+	MOVD $0, R2
+	MOVD $0, R4
+	MOVD $0, R5
+	BR   crosscall2(SB)
 
 // setg_trampoline(setg uintptr, g uintptr) - called from Go
 TEXT ·setg_trampoline(SB), NOSPLIT|NOFRAME, $0-16
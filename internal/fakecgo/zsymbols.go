@@ -104,6 +104,12 @@ func pthread_setspecific(key pthread_key_t, value unsafe.Pointer) int32 {
 	return int32(call5(pthread_setspecificABI0, uintptr(key), uintptr(value), 0, 0, 0))
 }
 
+//go:nosplit
+//go:norace
+func pthread_key_create(key *pthread_key_t, destructor unsafe.Pointer) int32 {
+	return int32(call5(pthread_key_createABI0, uintptr(unsafe.Pointer(key)), uintptr(destructor), 0, 0, 0))
+}
+
 //go:linkname _malloc _malloc
 var _malloc uint8
 var mallocABI0 = uintptr(unsafe.Pointer(&_malloc))
@@ -163,3 +169,7 @@ var pthread_cond_broadcastABI0 = uintptr(unsafe.Pointer(&_pthread_cond_broadcast
 //go:linkname _pthread_setspecific _pthread_setspecific
 var _pthread_setspecific uint8
 var pthread_setspecificABI0 = uintptr(unsafe.Pointer(&_pthread_setspecific))
+
+//go:linkname _pthread_key_create _pthread_key_create
+var _pthread_key_create uint8
+var pthread_key_createABI0 = uintptr(unsafe.Pointer(&_pthread_key_create))
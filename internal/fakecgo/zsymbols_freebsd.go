@@ -24,6 +24,7 @@ import "unsafe"
 //go:cgo_import_dynamic purego_pthread_mutex_unlock pthread_mutex_unlock "libthr.so.3"
 //go:cgo_import_dynamic purego_pthread_cond_broadcast pthread_cond_broadcast "libthr.so.3"
 //go:cgo_import_dynamic purego_pthread_setspecific pthread_setspecific "libthr.so.3"
+//go:cgo_import_dynamic purego_pthread_key_create pthread_key_create "libthr.so.3"
 //go:cgo_import_dynamic purego_pthread_attr_getstacksize pthread_attr_getstacksize "libthr.so.3"
 //go:cgo_import_dynamic purego_pthread_attr_destroy pthread_attr_destroy "libthr.so.3"
 
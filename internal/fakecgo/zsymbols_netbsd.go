@@ -25,6 +25,7 @@ import "unsafe"
 //go:cgo_import_dynamic purego_pthread_mutex_unlock pthread_mutex_unlock "libpthread.so"
 //go:cgo_import_dynamic purego_pthread_cond_broadcast pthread_cond_broadcast "libpthread.so"
 //go:cgo_import_dynamic purego_pthread_setspecific pthread_setspecific "libpthread.so"
+//go:cgo_import_dynamic purego_pthread_key_create pthread_key_create "libpthread.so"
 //go:cgo_import_dynamic purego_pthread_attr_getstacksize pthread_attr_getstacksize "libpthread.so"
 //go:cgo_import_dynamic purego_pthread_attr_destroy pthread_attr_destroy "libpthread.so"
 
@@ -53,3 +53,6 @@ TEXT _pthread_cond_broadcast(SB), NOSPLIT|NOFRAME, $0-0
 
 TEXT _pthread_setspecific(SB), NOSPLIT|NOFRAME, $0-0
 	JMP purego_pthread_setspecific(SB)
+
+TEXT _pthread_key_create(SB), NOSPLIT|NOFRAME, $0-0
+	JMP purego_pthread_key_create(SB)
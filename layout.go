@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// FieldLayout is the placement of a struct field as reported by offsetof and sizeof in C.
+type FieldLayout struct {
+	Offset uintptr
+	Size   uintptr
+}
+
+// LayoutError is returned by [CheckLayout] for every field of a Go struct that is not placed like the
+// field of the C struct, and when the size of the struct, and therefore its trailing padding, differs.
+type LayoutError struct {
+	Type reflect.Type
+	// Field is the index of the field in Type, or -1 if the size of the struct differs.
+	Field int
+	// Go is the layout of the field in the Go struct, and C the layout in the C struct.
+	// Only Size is set when Field is -1.
+	Go, C FieldLayout
+}
+
+func (e *LayoutError) Error() string {
+	if e.Field < 0 {
+		return fmt.Sprintf("purego: %s has size %d but the C struct has size %d", e.Type, e.Go.Size, e.C.Size)
+	}
+	return fmt.Sprintf("purego: field %s of %s has offset %d and size %d but the C field has offset %d and size %d",
+		e.Type.Field(e.Field).Name, e.Type, e.Go.Offset, e.Go.Size, e.C.Offset, e.C.Size)
+}
+
+// CheckLayout reports whether the Go struct goType is laid out like a C struct so that it can be passed to
+// or returned from a C function. cLayout holds the offset and size of each field of the C struct in order,
+// for example as printed by a small C program using offsetof and sizeof. If cLayout is nil, the layout
+// the C compiler gives to a struct with the same field types using natural alignment is used instead.
+// In both cases the size of the C struct is padded to the C alignment of its fields, which is 16 bytes
+// for [LongDouble], [Int128], [Uint128] and [Vec128] where Go aligns them to 8 bytes.
+//
+// A [*LayoutError] is returned for each field whose offset or size differs, and for a struct whose
+// size differs because of its trailing padding. The errors are combined with [errors.Join].
+//
+// Setting PUREGO_DEBUG=layoutcheck=1 in the environment makes [RegisterFunc] check every struct argument
+// and return type against the natural alignment layout.
+func CheckLayout(goType reflect.Type, cLayout []FieldLayout) error {
+	if goType.Kind() != reflect.Struct {
+		return fmt.Errorf("purego: %s is not a struct", goType)
+	}
+	fields, size, align, err := cStructLayout(goType)
+	if err != nil {
+		return err
+	}
+	if cLayout == nil {
+		cLayout = fields
+	} else {
+		if len(cLayout) != goType.NumField() {
+			return fmt.Errorf("purego: %s has %d fields but the C struct has %d", goType, goType.NumField(), len(cLayout))
+		}
+		// The C struct ends after its last field, padded to the alignment of its most aligned field.
+		var end uintptr
+		for _, c := range cLayout {
+			end = max(end, c.Offset+c.Size)
+		}
+		size = alignUp(end, align)
+	}
+
+	var errs []error
+	for i, c := range cLayout {
+		f := goType.Field(i)
+		g := FieldLayout{Offset: f.Offset, Size: f.Type.Size()}
+		if g != c {
+			errs = append(errs, &LayoutError{Type: goType, Field: i, Go: g, C: c})
+		}
+	}
+	if goType.Size() != size {
+		errs = append(errs, &LayoutError{Type: goType, Field: -1, Go: FieldLayout{Size: goType.Size()}, C: FieldLayout{Size: size}})
+	}
+	return errors.Join(errs...)
+}
+
+// cTypeLayout returns the size and alignment of the C type that corresponds to the Go type t.
+func cTypeLayout(t reflect.Type) (size, align uintptr, err error) {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1, 1, nil
+	case reflect.Int16, reflect.Uint16:
+		return 2, 2, nil
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4, 4, nil
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return 8, cAlign8(), nil
+	case reflect.Complex64:
+		return 8, 4, nil
+	case reflect.Complex128:
+		return 16, cAlign8(), nil
+	case reflect.Int, reflect.Uint, reflect.Uintptr, reflect.Pointer, reflect.UnsafePointer:
+		return unsafe.Sizeof(uintptr(0)), unsafe.Alignof(uintptr(0)), nil
+	case reflect.Array:
+		size, align, err := cTypeLayout(t.Elem())
+		if err != nil {
+			return 0, 0, err
+		}
+		return size * uintptr(t.Len()), align, nil
+	case reflect.Struct:
+		if isLongDoubleType(t) || isInt128Type(t) || isVectorType(t) {
+			return 16, 16, nil
+		}
+		_, size, align, err := cStructLayout(t)
+		return size, align, err
+	default:
+		return 0, 0, fmt.Errorf("purego: %s has no C counterpart", t)
+	}
+}
+
+// cStructLayout returns the offset and size of each field of the C struct that corresponds to the
+// Go struct t, and the size and alignment of the C struct.
+func cStructLayout(t reflect.Type) (fields []FieldLayout, size, align uintptr, err error) {
+	fields = make([]FieldLayout, t.NumField())
+	align = 1
+	for i := range fields {
+		fsize, falign, err := cTypeLayout(t.Field(i).Type)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		fields[i] = FieldLayout{Offset: alignUp(size, falign), Size: fsize}
+		size = fields[i].Offset + fsize
+		align = max(align, falign)
+	}
+	return fields, alignUp(size, align), align, nil
+}
+
+// cAlign8 returns the alignment of 8-byte integers and doubles inside a C struct.
+func cAlign8() uintptr {
+	if runtime.GOARCH == "386" && runtime.GOOS != "windows" {
+		// The i386 System V ABI aligns them to 4 bytes.
+		return 4
+	}
+	return 8
+}
+
+func alignUp(offset, align uintptr) uintptr {
+	return (offset + align - 1) &^ (align - 1)
+}
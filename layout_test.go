@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+type Mixed struct {
+	A int8
+	B int32
+	C int64
+	D int16
+	E float64
+	F int8
+}
+
+func TestCheckLayout(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "layouttest.so")
+	t.Logf("Build %v", libFileName)
+
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "layouttest", "layout_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("OpenLibrary(%q) failed: %v", libFileName, err)
+	}
+	defer func() {
+		if err := load.CloseLibrary(lib); err != nil {
+			t.Fatalf("failed to close library: %v", err)
+		}
+	}()
+
+	var mixedLayout func(out *uintptr)
+	purego.RegisterLibFunc(&mixedLayout, lib, "MixedLayout")
+	var out [13]uintptr
+	mixedLayout(&out[0])
+	cLayout := make([]purego.FieldLayout, 6)
+	for i := range cLayout {
+		cLayout[i] = purego.FieldLayout{Offset: out[2*i], Size: out[2*i+1]}
+	}
+	if err := purego.CheckLayout(reflect.TypeFor[Mixed](), cLayout); err != nil {
+		t.Errorf("CheckLayout with the C layout returned %v", err)
+	}
+
+	// The natural alignment layout computed by CheckLayout must match the C compiler field by field.
+	var nestedLayout func(out *uintptr)
+	purego.RegisterLibFunc(&nestedLayout, lib, "NestedLayout")
+	type nested struct {
+		A int8
+		M Mixed
+		S [5]int16
+		Z complex64
+		E int8
+	}
+	var nestedOut [11]uintptr
+	nestedLayout(&nestedOut[0])
+	for _, tt := range []struct {
+		typ reflect.Type
+		out []uintptr
+	}{
+		{reflect.TypeFor[Mixed](), out[:]},
+		{reflect.TypeFor[nested](), nestedOut[:]},
+	} {
+		natural, size, err := purego.NaturalLayout(tt.typ)
+		if err != nil {
+			t.Fatalf("NaturalLayout(%s) returned %v", tt.typ, err)
+		}
+		for i, f := range natural {
+			if c := (purego.FieldLayout{Offset: tt.out[2*i], Size: tt.out[2*i+1]}); f != c {
+				t.Errorf("%s.%s: natural layout %+v, C layout %+v", tt.typ, tt.typ.Field(i).Name, f, c)
+			}
+		}
+		if c := tt.out[len(tt.out)-1]; size != c {
+			t.Errorf("%s: natural size %d, C size %d", tt.typ, size, c)
+		}
+	}
+
+	// A Go struct that uses int64 for the C int.
+	type wrong struct {
+		A int8
+		B int64
+		C int64
+		D int16
+		E float64
+		F int8
+	}
+	err = purego.CheckLayout(reflect.TypeFor[wrong](), cLayout)
+	var layoutErr *purego.LayoutError
+	if !errors.As(err, &layoutErr) || layoutErr.Field != 1 {
+		t.Fatalf("CheckLayout returned %v, wanted a *LayoutError for field B", err)
+	}
+	if layoutErr.Go.Size != 8 || layoutErr.C.Size != 4 {
+		t.Errorf("LayoutError has sizes %d and %d, wanted 8 and 4", layoutErr.Go.Size, layoutErr.C.Size)
+	}
+	if err := purego.CheckLayout(reflect.TypeFor[struct{ S string }](), nil); err == nil {
+		t.Errorf("CheckLayout accepted a string field")
+	}
+}
+
+func TestCheckLayout_TailPadding(t *testing.T) {
+	// The fields are placed alike, but C pads the struct to the 16-byte alignment of __int128.
+	type padded struct {
+		A purego.Int128
+		B int64
+	}
+	cLayout := []purego.FieldLayout{{Offset: 0, Size: 16}, {Offset: 16, Size: 8}}
+	for _, layout := range [][]purego.FieldLayout{nil, cLayout} {
+		err := purego.CheckLayout(reflect.TypeFor[padded](), layout)
+		var layoutErr *purego.LayoutError
+		if !errors.As(err, &layoutErr) || layoutErr.Field != -1 {
+			t.Fatalf("CheckLayout(%v) returned %v, wanted a *LayoutError for the size", layout, err)
+		}
+		if layoutErr.Go.Size != 24 || layoutErr.C.Size != 32 {
+			t.Errorf("CheckLayout(%v) reported sizes %d and %d, wanted 24 and 32", layout, layoutErr.Go.Size, layoutErr.C.Size)
+		}
+		if errs := err.(interface{ Unwrap() []error }).Unwrap(); len(errs) != 1 {
+			t.Errorf("CheckLayout(%v) returned %d errors, wanted only the size: %v", layout, len(errs), err)
+		}
+	}
+}
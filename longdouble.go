@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// LongDouble is a C long double, which is wider than a float64 on the platforms that support it:
+// the x87 80-bit extended precision format on amd64 and the IEEE 754 quadruple precision format
+// on arm64 and riscv64. It is passed and returned like long double, either directly or as a field
+// of a struct, and is converted to and from Go numbers with [NewLongDouble],
+// [NewLongDoubleFromBigFloat], [LongDouble.Float64] and [LongDouble.BigFloat].
+//
+// LongDouble is only supported where long double is wider than double, which excludes Windows and
+// Apple ARM64 platforms; use float64 there instead. C aligns a long double to 16 bytes while Go
+// aligns a LongDouble to 8 bytes, so a struct field may need explicit padding, which [CheckLayout]
+// reports. LongDouble is not supported in callbacks, unions or ...any arguments.
+type LongDouble struct {
+	lo, hi uint64
+}
+
+const (
+	longDoubleBias   = 16383
+	longDoubleMaxExp = 0x7fff
+)
+
+// longDoubleX87 is true when long double is the x87 extended precision format,
+// which has an explicit integer bit, instead of the quadruple precision format.
+const longDoubleX87 = runtime.GOARCH == "amd64"
+
+// longDoublePrec returns the number of significand bits of a long double, including the integer bit.
+func longDoublePrec() uint {
+	if longDoubleX87 {
+		return 64
+	}
+	return 113
+}
+
+// NewLongDouble returns f as a LongDouble, which represents every float64 exactly.
+func NewLongDouble(f float64) LongDouble {
+	if math.IsNaN(f) {
+		if longDoubleX87 {
+			return LongDouble{lo: 0xc000_0000_0000_0000, hi: longDoubleMaxExp}
+		}
+		return LongDouble{hi: longDoubleMaxExp<<48 | 1<<47}
+	}
+	return NewLongDoubleFromBigFloat(new(big.Float).SetFloat64(f))
+}
+
+// NewLongDoubleFromBigFloat returns x rounded to the nearest LongDouble, with ties to even.
+// A value too large for a LongDouble is returned as an infinity.
+func NewLongDoubleFromBigFloat(x *big.Float) LongDouble {
+	prec := longDoublePrec()
+	mant := new(big.Int)
+	var exp int
+	switch {
+	case x.IsInf():
+		exp = longDoubleMaxExp
+	case x.Sign() != 0:
+		var m big.Float
+		// r is m × 2**e with 0.5 <= |m| < 1, so its integer bit is worth 2**(e-1).
+		r := new(big.Float).SetPrec(prec).Set(x)
+		exp = r.MantExp(&m) - 1 + longDoubleBias
+		switch {
+		case exp >= longDoubleMaxExp:
+			exp = longDoubleMaxExp
+		case exp > 0:
+			m.SetMantExp(&m, int(prec)).Int(mant)
+		default:
+			// A subnormal number has fewer significand bits, so x is rounded to those instead.
+			bits := int(prec) - 1 + exp
+			scale := longDoubleBias + int(prec) - 2
+			if bits > 0 {
+				s := new(big.Float).SetPrec(uint(bits)).Set(x)
+				s.SetMantExp(s, scale).Int(mant)
+			} else if s := new(big.Float).SetMantExp(x, scale); bits == 0 && s.Abs(s).Cmp(big.NewFloat(0.5)) > 0 {
+				// More than half of the smallest subnormal number rounds up to it.
+				mant.SetInt64(1)
+			}
+			exp = 0
+			if mant.BitLen() == int(prec) {
+				// Rounded up to the smallest normal number.
+				exp = 1
+			}
+		}
+		mant.Abs(mant)
+	}
+	return packLongDouble(x.Signbit(), exp, mant)
+}
+
+// packLongDouble encodes a long double from its sign, biased exponent and significand,
+// which includes the integer bit.
+func packLongDouble(neg bool, exp int, mant *big.Int) LongDouble {
+	var l LongDouble
+	if longDoubleX87 {
+		l.lo = mant.Uint64()
+		if exp == longDoubleMaxExp {
+			l.lo = 1 << 63
+		}
+		l.hi = uint64(exp)
+		if neg {
+			l.hi |= 1 << 15
+		}
+		return l
+	}
+	words := new(big.Int).Lsh(big.NewInt(1), 112)
+	frac := new(big.Int).And(mant, words.Sub(words, big.NewInt(1)))
+	l.lo = new(big.Int).And(frac, new(big.Int).SetUint64(math.MaxUint64)).Uint64()
+	l.hi = uint64(exp)<<48 | new(big.Int).Rsh(frac, 64).Uint64()
+	if neg {
+		l.hi |= 1 << 63
+	}
+	return l
+}
+
+// unpack returns the sign, biased exponent and significand of l, including the integer bit,
+// and whether l is a NaN.
+func (l LongDouble) unpack() (neg bool, exp int, mant *big.Int, nan bool) {
+	if longDoubleX87 {
+		neg = l.hi>>15&1 == 1
+		exp = int(l.hi & longDoubleMaxExp)
+		mant = new(big.Int).SetUint64(l.lo)
+		return neg, exp, mant, exp == longDoubleMaxExp && l.lo<<1 != 0
+	}
+	neg = l.hi>>63 == 1
+	exp = int(l.hi >> 48 & longDoubleMaxExp)
+	mant = new(big.Int).Lsh(new(big.Int).SetUint64(l.hi&(1<<48-1)), 64)
+	mant.Or(mant, new(big.Int).SetUint64(l.lo))
+	nan = exp == longDoubleMaxExp && mant.Sign() != 0
+	if exp != 0 {
+		mant.SetBit(mant, 112, 1)
+	}
+	return neg, exp, mant, nan
+}
+
+// Float64 returns l rounded to the nearest float64. A NaN is returned as a NaN.
+func (l LongDouble) Float64() float64 {
+	x := l.BigFloat()
+	if x == nil {
+		return math.NaN()
+	}
+	f, _ := x.Float64()
+	return f
+}
+
+// BigFloat returns l as a *big.Float with the precision of a long double.
+// It returns nil if l is a NaN, which a big.Float cannot represent.
+func (l LongDouble) BigFloat() *big.Float {
+	neg, exp, mant, nan := l.unpack()
+	if nan {
+		return nil
+	}
+	prec := longDoublePrec()
+	x := new(big.Float).SetPrec(prec)
+	if exp == longDoubleMaxExp {
+		x.SetInf(neg)
+		return x
+	}
+	x.SetInt(mant)
+	x.SetMantExp(x, max(exp, 1)-longDoubleBias-int(prec)+1)
+	if neg {
+		x.Neg(x)
+	}
+	return x
+}
+
+// isLongDoubleType reports whether t is LongDouble.
+func isLongDoubleType(t reflect.Type) bool {
+	return t == reflect.TypeFor[LongDouble]()
+}
+
+// containsLongDouble reports whether t is or contains a LongDouble.
+func containsLongDouble(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		if isLongDoubleType(t) {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if containsLongDouble(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return containsLongDouble(t.Elem())
+	}
+	return false
+}
+
+// homogeneousLongDoubles reports whether t is a LongDouble or an aggregate of only LongDouble,
+// and returns how many of them t holds.
+func homogeneousLongDoubles(t reflect.Type) (n int, ok bool) {
+	return homogeneousAggregate(t, isLongDoubleType)
+}
+
+// homogeneousAggregate reports whether t is a type for which is reports true or an aggregate
+// of only such a type, and returns how many of them t holds.
+func homogeneousAggregate(t reflect.Type, is func(reflect.Type) bool) (n int, ok bool) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if is(t) {
+			return 1, true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			m, ok := homogeneousAggregate(t.Field(i).Type, is)
+			if !ok {
+				return 0, false
+			}
+			n += m
+		}
+		return n, n > 0
+	case reflect.Array:
+		m, ok := homogeneousAggregate(t.Elem(), is)
+		return m * t.Len(), ok && t.Len() > 0
+	}
+	return 0, false
+}
+
+// ensureLongDoubleSupported panics if long double cannot be passed to or returned from C
+// on the current platform.
+func ensureLongDoubleSupported() {
+	if runtime.GOOS == "windows" || isDarwin && runtime.GOARCH == "arm64" {
+		panic("purego: LongDouble is not supported on " + runtime.GOOS + "/" + runtime.GOARCH + " where long double is double; use float64 instead")
+	}
+	switch runtime.GOARCH {
+	case "amd64", "arm64", "riscv64":
+	default:
+		panic("purego: LongDouble is only supported on amd64, arm64 and riscv64")
+	}
+}
+
+// addLongDouble passes v if it is a LongDouble or a struct holding one that is not passed by reference,
+// and reports whether it did. On arm64 addQuad places the two halves of a long double in the next vector
+// register, which addLongDouble checks is available.
+func addLongDouble(v reflect.Value, addInt, addStack func(uintptr), addQuad func(lo, hi uintptr), numInts, numFloats, numStack *int) bool {
+	if !containsLongDouble(v.Type()) {
+		return false
+	}
+	switch runtime.GOARCH {
+	case "amd64":
+		// The x87 class is always passed in memory, which is aligned to 16 bytes like long double.
+		if *numStack%2 != 0 {
+			addStack(0)
+		}
+		addWords(v, addStack)
+		return true
+	case "arm64":
+		// A homogeneous aggregate of up to four long doubles is passed one per vector register.
+		// Any other struct holding a long double is larger than 16 bytes and passed by reference.
+		n, ok := homogeneousLongDoubles(v.Type())
+		if !ok || n > 4 {
+			return false
+		}
+		addQuads(v, n, addStack, addQuad, numFloats, numStack)
+		return true
+	case "riscv64":
+		// A quad is passed like a 128-bit integer.
+		if !isLongDoubleType(v.Type()) {
+			return false
+		}
+		addRegisterPair(v, addInt, addStack, numInts, numStack)
+		return true
+	}
+	return false
+}
+
+// addQuads passes the n 16-byte members of v one per vector register, or the whole of v on the stack
+// aligned to 16 bytes if they do not all fit, after which no later argument uses the vector registers.
+func addQuads(v reflect.Value, n int, addStack func(uintptr), addQuad func(lo, hi uintptr), numFloats, numStack *int) {
+	if *numFloats+n > numOfFloatRegisters() {
+		*numFloats = numOfFloatRegisters()
+		if *numStack%2 != 0 {
+			addStack(0)
+		}
+		addWords(v, addStack)
+		return
+	}
+	var words [8]uintptr
+	reflect.NewAt(v.Type(), unsafe.Pointer(&words[0])).Elem().Set(v)
+	for i := range n {
+		addQuad(words[2*i], words[2*i+1])
+	}
+}
+
+// addWords passes the memory of v, whose size is a multiple of 8 bytes, as pointer-sized words.
+func addWords(v reflect.Value, add func(uintptr)) {
+	if !v.CanAddr() {
+		tmp := reflect.New(v.Type()).Elem()
+		tmp.Set(v)
+		v = tmp
+	}
+	ptr := v.Addr().UnsafePointer()
+	for off := uintptr(0); off < v.Type().Size(); off += 8 {
+		add(*(*uintptr)(unsafe.Add(ptr, off)))
+	}
+}
+
+// longDoubleResult returns the result of type t from syscall if t is a LongDouble or a struct
+// holding one that is returned in registers, and reports whether it is.
+func longDoubleResult(t reflect.Type, syscall *syscallArgs) (reflect.Value, bool) {
+	if !containsLongDouble(t) {
+		return reflect.Value{}, false
+	}
+	var words [2]uintptr
+	switch runtime.GOARCH {
+	case "amd64":
+		// Only a struct of exactly one long double is not returned in memory. It is returned in ST(0),
+		// which syscallX stores over f1 and f2 as 10 bytes.
+		if t.Size() != 16 {
+			return reflect.Value{}, false
+		}
+		words[0], words[1] = syscall.f1, syscall.f2&0xffff
+	case "arm64":
+		n, ok := homogeneousLongDoubles(t)
+		if !ok || n > 4 {
+			return reflect.Value{}, false
+		}
+		return quadsResult(t, syscall), true
+	case "riscv64":
+		if !isLongDoubleType(t) {
+			return reflect.Value{}, false
+		}
+		words[0], words[1] = syscall.a1, syscall.a2
+	default:
+		return reflect.Value{}, false
+	}
+	v := reflect.New(t).Elem()
+	v.Set(reflect.NewAt(t, unsafe.Pointer(&words[0])).Elem())
+	return v, true
+}
+
+// quadsResult returns the result of type t from the whole of the first four vector registers,
+// which hold its 16-byte members in order.
+func quadsResult(t reflect.Type, syscall *syscallArgs) reflect.Value {
+	words := [8]uintptr{syscall.f1, syscall.f1hi, syscall.f2, syscall.f2hi, syscall.f3, syscall.f3hi, syscall.f4, syscall.f4hi}
+	v := reflect.New(t).Elem()
+	v.Set(reflect.NewAt(t, unsafe.Pointer(&words[0])).Elem())
+	return v
+}
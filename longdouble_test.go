@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || linux) && (amd64 || arm64)
+
+package purego_test
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+func openLongDoubleTest(t *testing.T) uintptr {
+	t.Helper()
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		t.Skip("long double is double on darwin/arm64")
+	}
+	libFileName := filepath.Join(t.TempDir(), "longdoubletest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "longdoubletest", "longdouble_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+// bigThird returns 1/3 with prec bits of precision.
+func bigThird(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec).Quo(big.NewFloat(1), big.NewFloat(3))
+}
+
+func TestLongDouble_Conversion(t *testing.T) {
+	for _, f := range []float64{0, math.Copysign(0, -1), 1.5, -2.25, 1.0 / 3, math.MaxFloat64, math.SmallestNonzeroFloat64, math.Inf(1), math.Inf(-1)} {
+		if got := purego.NewLongDouble(f).Float64(); math.Float64bits(got) != math.Float64bits(f) {
+			t.Errorf("NewLongDouble(%v).Float64() = %v", f, got)
+		}
+	}
+	nan := purego.NewLongDouble(math.NaN())
+	if !math.IsNaN(nan.Float64()) || nan.BigFloat() != nil {
+		t.Errorf("NewLongDouble(NaN) = %v, %v, want NaN, nil", nan.Float64(), nan.BigFloat())
+	}
+
+	third := purego.NewLongDoubleFromBigFloat(bigThird(200)).BigFloat()
+	if prec := third.Prec(); prec != 64 && prec != 113 {
+		t.Fatalf("BigFloat().Prec() = %d, want 64 or 113", prec)
+	}
+	if want := bigThird(third.Prec()); third.Cmp(want) != 0 {
+		t.Errorf("1/3 = %v, want %v", third.Text('g', 40), want.Text('g', 40))
+	}
+	if f, _ := third.Float64(); new(big.Float).SetFloat64(f).Cmp(third) == 0 {
+		t.Errorf("1/3 = %v has no more precision than a float64", third.Text('g', 40))
+	}
+
+	for _, exp := range []int{16383, -16382, -16400, -16440} {
+		x := new(big.Float).SetMantExp(big.NewFloat(1), exp)
+		if got := purego.NewLongDoubleFromBigFloat(x).BigFloat(); got.Cmp(x) != 0 {
+			t.Errorf("2**%d = %v, want %v", exp, got, x)
+		}
+	}
+	huge := new(big.Float).SetMantExp(big.NewFloat(1), 16384)
+	if got := purego.NewLongDoubleFromBigFloat(huge).BigFloat(); !got.IsInf() {
+		t.Errorf("2**16384 = %v, want +Inf", got)
+	}
+}
+
+type (
+	box struct {
+		Value purego.LongDouble
+	}
+	longDoublePair struct {
+		A, B purego.LongDouble
+	}
+	tagged struct {
+		Tag   int32
+		_     [12]byte
+		Value purego.LongDouble
+	}
+)
+
+func TestLongDouble(t *testing.T) {
+	lib := openLongDoubleTest(t)
+
+	var addLongDouble func(a, b purego.LongDouble) purego.LongDouble
+	purego.RegisterLibFunc(&addLongDouble, lib, "addLongDouble")
+	if got := addLongDouble(purego.NewLongDouble(1.5), purego.NewLongDouble(2.25)).Float64(); got != 3.75 {
+		t.Errorf("addLongDouble() = %v, want 3.75", got)
+	}
+
+	var third func() purego.LongDouble
+	purego.RegisterLibFunc(&third, lib, "third")
+	got := third().BigFloat()
+	if want := bigThird(got.Prec()); got.Cmp(want) != 0 {
+		t.Errorf("third() = %v, want %v", got.Text('g', 40), want.Text('g', 40))
+	}
+	var isThird func(x purego.LongDouble) bool
+	purego.RegisterLibFunc(&isThird, lib, "isThird")
+	if !isThird(purego.NewLongDoubleFromBigFloat(bigThird(200))) {
+		t.Error("isThird() = false, want true")
+	}
+
+	var mixed func(a int32, b purego.LongDouble, c float64, d purego.LongDouble, e int64) purego.LongDouble
+	purego.RegisterLibFunc(&mixed, lib, "mixed")
+	if got := mixed(1, purego.NewLongDouble(2), 3, purego.NewLongDouble(4), 5).Float64(); got != 15 {
+		t.Errorf("mixed() = %v, want 15", got)
+	}
+
+	var afterInts func(a, b, c, d, e, f, g int64, x purego.LongDouble) purego.LongDouble
+	purego.RegisterLibFunc(&afterInts, lib, "afterInts")
+	if got := afterInts(1, 2, 3, 4, 5, 6, 7, purego.NewLongDouble(0.5)).Float64(); got != 28.5 {
+		t.Errorf("afterInts() = %v, want 28.5", got)
+	}
+
+	var sum9 func(a, b, c, d, e, f, g, h, i purego.LongDouble) purego.LongDouble
+	purego.RegisterLibFunc(&sum9, lib, "sum9")
+	var args [9]purego.LongDouble
+	for i := range args {
+		args[i] = purego.NewLongDouble(float64(i + 1))
+	}
+	if got := sum9(args[0], args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8]).Float64(); got != 45 {
+		t.Errorf("sum9() = %v, want 45", got)
+	}
+
+	var makeBox func(value float64) box
+	purego.RegisterLibFunc(&makeBox, lib, "makeBox")
+	if got := makeBox(2.5).Value.Float64(); got != 2.5 {
+		t.Errorf("makeBox() = %v, want 2.5", got)
+	}
+
+	var pairSum func(p longDoublePair) purego.LongDouble
+	purego.RegisterLibFunc(&pairSum, lib, "pairSum")
+	if got := pairSum(longDoublePair{purego.NewLongDouble(1), purego.NewLongDouble(2)}).Float64(); got != 3 {
+		t.Errorf("pairSum() = %v, want 3", got)
+	}
+	var makePair func(a, b purego.LongDouble) longDoublePair
+	purego.RegisterLibFunc(&makePair, lib, "makePair")
+	if p := makePair(purego.NewLongDouble(1), purego.NewLongDouble(2)); p.A.Float64() != 1 || p.B.Float64() != 2 {
+		t.Errorf("makePair() = {%v, %v}, want {1, 2}", p.A.Float64(), p.B.Float64())
+	}
+
+	if err := purego.CheckLayout(reflect.TypeFor[tagged](), nil); err != nil {
+		t.Errorf("CheckLayout() = %v", err)
+	}
+	var taggedValue func(t tagged) purego.LongDouble
+	purego.RegisterLibFunc(&taggedValue, lib, "taggedValue")
+	if got := taggedValue(tagged{Tag: 3, Value: purego.NewLongDouble(0.5)}).Float64(); got != 3.5 {
+		t.Errorf("taggedValue() = %v, want 3.5", got)
+	}
+	var makeTagged func(tag int32, value purego.LongDouble) tagged
+	purego.RegisterLibFunc(&makeTagged, lib, "makeTagged")
+	if tg := makeTagged(3, purego.NewLongDouble(0.5)); tg.Tag != 3 || tg.Value.Float64() != 0.5 {
+		t.Errorf("makeTagged() = {%d, %v}, want {3, 0.5}", tg.Tag, tg.Value.Float64())
+	}
+}
+
+func TestLongDouble_Unsupported(t *testing.T) {
+	var fn func(x ...any)
+	purego.RegisterFunc(&fn, 1)
+	defer func() {
+		if recover() == nil {
+			t.Error("passing a LongDouble in ...any did not panic")
+		}
+	}()
+	fn(purego.NewLongDouble(1))
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// NewFunc0 returns a Go function that calls the C function cfn without arguments.
+//
+// NewFunc0 through NewFunc8 follow the same type conversions as [RegisterFunc], but the returned
+// function is built for the type parameters at compile time instead of through reflect.MakeFunc,
+// so a call only copies its arguments into place. Use struct{} as R when the C function returns void.
+//
+// Only arguments that are passed in a single register or stack slot are supported: strings,
+// booleans, integers, floats, pointers, [Pinned] values, slices and functions. A panic is produced for any
+// other argument type, such as structs or ...any, and on Darwin ARM64 when an argument
+// would be passed on the stack. Use [RegisterFunc] for those signatures.
+func NewFunc0[R any](cfn uintptr) func() R {
+	p := newTypedPlan(reflect.TypeFor[func() R](), cfn)
+	return func() R {
+		return callTyped[R](p, cfn, nil)
+	}
+}
+
+// NewFunc1 is like [NewFunc0] for a C function with 1 argument.
+func NewFunc1[R, A1 any](cfn uintptr) func(A1) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1) R](), cfn)
+	return func(a1 A1) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1)})
+	}
+}
+
+// NewFunc2 is like [NewFunc0] for a C function with 2 arguments.
+func NewFunc2[R, A1, A2 any](cfn uintptr) func(A1, A2) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2) R](), cfn)
+	return func(a1 A1, a2 A2) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2)})
+	}
+}
+
+// NewFunc3 is like [NewFunc0] for a C function with 3 arguments.
+func NewFunc3[R, A1, A2, A3 any](cfn uintptr) func(A1, A2, A3) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3)})
+	}
+}
+
+// NewFunc4 is like [NewFunc0] for a C function with 4 arguments.
+func NewFunc4[R, A1, A2, A3, A4 any](cfn uintptr) func(A1, A2, A3, A4) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3, A4) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3, a4 A4) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3, a4)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3), unsafe.Pointer(&a4)})
+	}
+}
+
+// NewFunc5 is like [NewFunc0] for a C function with 5 arguments.
+func NewFunc5[R, A1, A2, A3, A4, A5 any](cfn uintptr) func(A1, A2, A3, A4, A5) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3, A4, A5) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3, a4 A4, a5 A5) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3, a4, a5)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3), unsafe.Pointer(&a4), unsafe.Pointer(&a5)})
+	}
+}
+
+// NewFunc6 is like [NewFunc0] for a C function with 6 arguments.
+func NewFunc6[R, A1, A2, A3, A4, A5, A6 any](cfn uintptr) func(A1, A2, A3, A4, A5, A6) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3, A4, A5, A6) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3, a4 A4, a5 A5, a6 A6) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3, a4, a5, a6)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3), unsafe.Pointer(&a4), unsafe.Pointer(&a5), unsafe.Pointer(&a6)})
+	}
+}
+
+// NewFunc7 is like [NewFunc0] for a C function with 7 arguments.
+func NewFunc7[R, A1, A2, A3, A4, A5, A6, A7 any](cfn uintptr) func(A1, A2, A3, A4, A5, A6, A7) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3, A4, A5, A6, A7) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3, a4 A4, a5 A5, a6 A6, a7 A7) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3, a4, a5, a6, a7)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3), unsafe.Pointer(&a4), unsafe.Pointer(&a5), unsafe.Pointer(&a6), unsafe.Pointer(&a7)})
+	}
+}
+
+// NewFunc8 is like [NewFunc0] for a C function with 8 arguments.
+func NewFunc8[R, A1, A2, A3, A4, A5, A6, A7, A8 any](cfn uintptr) func(A1, A2, A3, A4, A5, A6, A7, A8) R {
+	p := newTypedPlan(reflect.TypeFor[func(A1, A2, A3, A4, A5, A6, A7, A8) R](), cfn)
+	return func(a1 A1, a2 A2, a3 A3, a4 A4, a5 A5, a6 A6, a7 A7, a8 A8) R {
+		if debug.cgocheck {
+			cgoCheckArgs(a1, a2, a3, a4, a5, a6, a7, a8)
+		}
+		return callTyped[R](p, cfn, []unsafe.Pointer{unsafe.Pointer(&a1), unsafe.Pointer(&a2), unsafe.Pointer(&a3), unsafe.Pointer(&a4), unsafe.Pointer(&a5), unsafe.Pointer(&a6), unsafe.Pointer(&a7), unsafe.Pointer(&a8)})
+	}
+}
+
+// newTypedPlan returns the callPlan of the function type ty created by one of the NewFuncN functions.
+// It panics if ty has arguments that are not placed at a fixed location.
+func newTypedPlan(ty reflect.Type, cfn uintptr) *callPlan {
+	if cfn == 0 {
+		panic("purego: cfn is nil")
+	}
+	if out := ty.Out(0); out.Kind() == reflect.Struct && out.Size() == 0 {
+		// struct{} stands for a C function that returns void.
+		ty = reflect.FuncOf(funcIn(ty), nil, false)
+	}
+	if err := checkFuncType(ty); err != nil {
+		panic(err)
+	}
+	if ty.NumOut() > 0 && isOwnedStringType(ty.Out(0)) {
+		panic("purego: OwnedString is not supported by NewFunc; use RegisterLibFunc instead")
+	}
+	p := planFor(ty)
+	if !p.fixed {
+		panic("purego: " + ty.String() + " is not supported by NewFunc; use RegisterFunc instead")
+	}
+	return p
+}
+
+func funcIn(ty reflect.Type) []reflect.Type {
+	in := make([]reflect.Type, ty.NumIn())
+	for i := range in {
+		in[i] = ty.In(i)
+	}
+	return in
+}
+
+// callTyped calls cfn with the arguments pointed to by args and returns the result.
+func callTyped[R any](p *callPlan, cfn uintptr, args []unsafe.Pointer) (r R) {
+	if v, _ := p.invoke(cfn, 0, nil, args, unsafe.Pointer(&r)); v.IsValid() {
+		r = v.Interface().(R)
+	}
+	return r
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+
+	"github.com/ebitengine/purego/internal/strings"
+)
+
+// NullString is a C string that may be NULL. As an argument of a function registered with [RegisterFunc]
+// or created by [NewFunc1] and the like, String is copied into a null-terminated string like a string
+// argument, or NULL is passed when Valid is false. As a result, or as an argument of a callback created
+// by [NewCallback], Valid reports whether the char* is not NULL and String holds a copy of it.
+//
+// A *string can be used in the same places, where nil stands for NULL.
+type NullString struct {
+	String string
+	Valid  bool // Valid is true if String is not NULL
+}
+
+// isNullStringType reports whether ty is NullString.
+func isNullStringType(ty reflect.Type) bool {
+	return ty == reflect.TypeFor[NullString]()
+}
+
+// isStringPointerType reports whether ty is a pointer to a string, which is passed as a C string or NULL.
+func isStringPointerType(ty reflect.Type) bool {
+	return ty.Kind() == reflect.Pointer && ty.Elem().Kind() == reflect.String
+}
+
+// nullableCString returns a C string copied from v, which must be a NullString or a *string,
+// or nil if v is not valid or is nil.
+func nullableCString(v reflect.Value) *byte {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		return strings.CString(v.Elem().String())
+	}
+	if !v.Field(1).Bool() {
+		return nil
+	}
+	return strings.CString(v.Field(0).String())
+}
+
+// goNullString returns a NullString holding a copy of the C string p.
+func goNullString(p uintptr) NullString {
+	if p == 0 {
+		return NullString{}
+	}
+	return NullString{String: strings.GoString(p), Valid: true}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// OwnedString is a string returned by a C function as a char* that the caller must free, such as the
+// result of strdup or g_strdup_printf. The string is copied into Go memory and the char* is then freed
+// with the deallocator of the library set by [SetFreeFunc]. NULL is returned as an empty string.
+//
+// OwnedString can only be the result of a function registered with [RegisterLibFunc] or
+// [TryRegisterLibFunc] for a library that has a deallocator. As an argument it is passed like a string.
+//
+//	purego.SetFreeFunc(glib, "g_free")
+//	var getCurrentDir func() purego.OwnedString
+//	purego.RegisterLibFunc(&getCurrentDir, glib, "g_get_current_dir")
+type OwnedString string
+
+// freeFuncs holds the deallocators set by SetFreeFunc for each library handle.
+var freeFuncs struct {
+	lock sync.Mutex
+	fns  map[uintptr]uintptr
+}
+
+// SetFreeFunc sets the C function that frees the [OwnedString] results of functions registered
+// with [RegisterLibFunc] for handle to the symbol name, which takes the pointer to free as its
+// only argument, like free or g_free. It must be called before those functions are registered.
+// It panics if it can't find the name symbol.
+func SetFreeFunc(handle uintptr, name string) {
+	sym, err := loadSymbol(handle, name)
+	if err != nil {
+		panic(err)
+	}
+	freeFuncs.lock.Lock()
+	defer freeFuncs.lock.Unlock()
+	if freeFuncs.fns == nil {
+		freeFuncs.fns = make(map[uintptr]uintptr)
+	}
+	freeFuncs.fns[handle] = sym
+}
+
+// freeFuncFor returns the deallocator set by SetFreeFunc for handle, or 0 if there is none.
+func freeFuncFor(handle uintptr) uintptr {
+	freeFuncs.lock.Lock()
+	defer freeFuncs.lock.Unlock()
+	return freeFuncs.fns[handle]
+}
+
+// isOwnedStringType reports whether ty is OwnedString.
+func isOwnedStringType(ty reflect.Type) bool {
+	return ty == reflect.TypeFor[OwnedString]()
+}
+
+// freeCString calls the C deallocator free with the pointer p.
+func freeCString(free, p uintptr) {
+	if runtime.GOOS == "windows" && runtime.GOARCH != "arm64" {
+		// Windows amd64, 386, and arm use syscall.SyscallN.
+		syscall_syscallN(free, p)
+		return
+	}
+	var sysargs [maxArgs]uintptr
+	var floats [maxArgs]uintptr
+	sysargs[0] = p
+	thePool.Put(syscall_SyscallN(free, sysargs[:], floats[:], 0))
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+)
+
+// Pinned is a pointer to Go memory that stays pinned after it is passed to a C function, so that
+// the C function may keep it, for example as the buffer of an asynchronous read or as the context
+// of a registered handler. A Pinned[T] argument of a function registered with [RegisterFunc] or
+// created by [NewFunc1] and the like is passed as a T*.
+//
+// The memory is pinned with a [runtime.Pinner] by [Pin] until [Pinned.Unpin] is called, which
+// follows the cgo rules for Go pointers that C keeps after a call returns. Unpin must be called
+// once C no longer uses the memory. The Go objects that the memory points to are not pinned.
+//
+// Pinned cannot be the result of a function or an argument of a callback.
+type Pinned[T any] struct {
+	// ptr must be the first field since it is passed to C as the pointer.
+	ptr    *T
+	pinner *runtime.Pinner
+}
+
+// Pin pins the Go object ptr points to until [Pinned.Unpin] is called. To pin the elements
+// of a slice, pass a pointer to its first element. A nil ptr returns a Pinned that is passed
+// to C as NULL.
+func Pin[T any](ptr *T) Pinned[T] {
+	if ptr == nil {
+		return Pinned[T]{}
+	}
+	pinner := new(runtime.Pinner)
+	pinner.Pin(ptr)
+	return Pinned[T]{ptr: ptr, pinner: pinner}
+}
+
+// Pointer returns the pointer passed to [Pin].
+func (p Pinned[T]) Pointer() *T {
+	return p.ptr
+}
+
+// Unpin unpins the memory. The caller must ensure that C code no longer uses it.
+// Calling Unpin more than once has no effect.
+func (p Pinned[T]) Unpin() {
+	if p.pinner != nil {
+		p.pinner.Unpin()
+	}
+}
+
+func (p Pinned[T]) pinned() {}
+
+// pinnedArg is implemented by every Pinned type and by the structs that embed one.
+type pinnedArg interface {
+	pinned()
+}
+
+// isPinnedType reports whether ty is a Pinned type.
+func isPinnedType(ty reflect.Type) bool {
+	return hasMarker(ty, reflect.TypeFor[pinnedArg]())
+}
+
+// hasMarker reports whether the struct type t has the method of the marker interface m itself.
+// Since the method is unexported, only the purego type that declares it has it, while a struct
+// that embeds that type implements m through the promoted method.
+func hasMarker(t, m reflect.Type) bool {
+	if t.Kind() != reflect.Struct || !t.Implements(m) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.Type.Implements(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// pinnedPointer returns the pointer held by v, which must be a Pinned value.
+func pinnedPointer(v reflect.Value) uintptr {
+	return uintptr(v.Field(0).UnsafePointer())
+}
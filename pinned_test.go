@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+func TestPinned(t *testing.T) {
+	libFileName := filepath.Join(t.TempDir(), "pintest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "pintest", "pin_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(libFileName)
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	defer load.CloseLibrary(lib)
+
+	var keepPointer func(p purego.Pinned[int64])
+	purego.RegisterLibFunc(&keepPointer, lib, "keepPointer")
+	var keepPointerStack func(a, b, c, d, e, f, g, h int, p purego.Pinned[int64])
+	purego.RegisterLibFunc(&keepPointerStack, lib, "keepPointerStack")
+	var keepPointerAny func(args ...any)
+	purego.RegisterLibFunc(&keepPointerAny, lib, "keepPointer")
+	sym, err := load.OpenSymbol(lib, "keepPointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keepPointerTyped := purego.NewFunc1[struct{}, purego.Pinned[int64]](sym)
+	var readKept func() int64
+	purego.RegisterLibFunc(&readKept, lib, "readKept")
+	var writeKept func(v int64)
+	purego.RegisterLibFunc(&writeKept, lib, "writeKept")
+
+	keeps := map[string]func(p purego.Pinned[int64]){
+		"RegisterFunc": keepPointer,
+		"Stack": func(p purego.Pinned[int64]) {
+			keepPointerStack(1, 2, 3, 4, 5, 6, 7, 8, p)
+		},
+		"Any": func(p purego.Pinned[int64]) {
+			keepPointerAny(p)
+		},
+		"NewFunc": func(p purego.Pinned[int64]) {
+			keepPointerTyped(p)
+		},
+	}
+	for name, keep := range keeps {
+		t.Run(name, func(t *testing.T) {
+			p := purego.Pin(new(int64))
+			defer p.Unpin()
+			*p.Pointer() = 42
+			keep(p)
+			// C keeps using the memory after the call returned.
+			runtime.GC()
+			if got := readKept(); got != 42 {
+				t.Errorf("readKept() = %d, want 42", got)
+			}
+			writeKept(7)
+			if got := *p.Pointer(); got != 7 {
+				t.Errorf("value written by C = %d, want 7", got)
+			}
+		})
+	}
+}
+
+func TestPinned_Unsupported(t *testing.T) {
+	var fn func() purego.Pinned[int64]
+	if err := purego.TryRegisterFunc(&fn, 1); err == nil {
+		t.Error("TryRegisterFunc accepted a Pinned result")
+	}
+}
+
+// pinnedBox embeds a Pinned, which makes it a struct of two pointers rather than a Pinned.
+type pinnedBox struct {
+	purego.Pinned[int64]
+}
+
+func TestPinned_Embedded(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skip("struct results are only supported on amd64 and arm64")
+	}
+	var fn func() pinnedBox
+	if err := purego.TryRegisterFunc(&fn, 1); err != nil {
+		t.Errorf("TryRegisterFunc rejected a struct embedding a Pinned result: %v", err)
+	}
+}
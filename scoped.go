@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import "reflect"
+
+// ScopedCallback is a Go function that is passed to a C function as a function pointer that is only
+// valid until the C function returns, such as the comparison function of qsort. A ScopedCallback[F]
+// argument of a function registered with [RegisterFunc] or created by [NewFunc1] and the like is
+// passed like an F, except that its callback is released with [ReleaseCallback] when the call returns
+// instead of being kept for the lifetime of the process. A new closure can then be passed for every
+// call without using up the callbacks. On Windows, where callbacks cannot be released, it is passed
+// like an F.
+//
+// The C function must not keep the function pointer after it returns.
+type ScopedCallback[F any] struct {
+	fn F
+}
+
+// Scoped returns fn as a ScopedCallback. F must be a function type.
+func Scoped[F any](fn F) ScopedCallback[F] {
+	return ScopedCallback[F]{fn: fn}
+}
+
+func (c ScopedCallback[F]) scopedFunc() any {
+	return c.fn
+}
+
+// scopedCallbackArg is implemented by every ScopedCallback type and by the structs that embed one.
+type scopedCallbackArg interface {
+	scopedFunc() any
+}
+
+// isScopedCallbackType reports whether ty is a ScopedCallback type.
+func isScopedCallbackType(ty reflect.Type) bool {
+	return hasMarker(ty, reflect.TypeFor[scopedCallbackArg]())
+}
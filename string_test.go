@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+func openStringTest(t *testing.T) uintptr {
+	t.Helper()
+	libFileName := filepath.Join(t.TempDir(), "stringtest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "stringtest", "string_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+func TestStringSliceArgument(t *testing.T) {
+	lib := openStringTest(t)
+
+	type name string
+	var joinStrings func(arr []string, out []byte, size uintptr) int32
+	purego.RegisterLibFunc(&joinStrings, lib, "joinStrings")
+	var joinNames func(arr []name, out []byte, size uintptr) int32
+	purego.RegisterLibFunc(&joinNames, lib, "joinStrings")
+	var joinAny func(args ...any) int32
+	purego.RegisterLibFunc(&joinAny, lib, "joinStrings")
+	sym, err := load.OpenSymbol(lib, "joinStrings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	joinTyped := purego.NewFunc3[int32, []string, []byte, uintptr](sym)
+
+	joins := map[string]func(arr []string, out []byte) int32{
+		"RegisterFunc": func(arr []string, out []byte) int32 {
+			return joinStrings(arr, out, uintptr(len(out)))
+		},
+		"NamedElem": func(arr []string, out []byte) int32 {
+			var names []name
+			if arr != nil {
+				names = []name{}
+			}
+			for _, s := range arr {
+				names = append(names, name(s))
+			}
+			return joinNames(names, out, uintptr(len(out)))
+		},
+		"Any": func(arr []string, out []byte) int32 {
+			return joinAny(arr, out, uintptr(len(out)))
+		},
+		"NewFunc": func(arr []string, out []byte) int32 {
+			return joinTyped(arr, out, uintptr(len(out)))
+		},
+	}
+	tests := []struct {
+		arr  []string
+		want string
+		n    int32
+	}{
+		{[]string{"ls", "-l", "/tmp\x00"}, "ls,-l,/tmp", 3},
+		{[]string{}, "", 0},
+		{nil, "", -1},
+	}
+	for name, join := range joins {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				out := make([]byte, 64)
+				if n := join(tt.arr, out); n != tt.n {
+					t.Errorf("joinStrings(%q) = %d, want %d", tt.arr, n, tt.n)
+				}
+				if got := string(out[:max(0, bytes.IndexByte(out, 0))]); got != tt.want {
+					t.Errorf("joinStrings(%q) wrote %q, want %q", tt.arr, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStringSliceArgumentCgoCheck(t *testing.T) {
+	// The array of C strings passed for a []string holds Go pointers,
+	// so it must pass the cgo pointer check while the C function runs.
+	purego.CheckCStringArray([]string{"ls", "-l", "/tmp"})
+	purego.CheckCStringArray([]string{})
+	purego.CheckCStringArray(nil)
+}
+
+func TestStringSliceReturn(t *testing.T) {
+	lib := openStringTest(t)
+
+	var splitString func(s string, count *int32) []string
+	purego.RegisterLibFunc(&splitString, lib, "splitString")
+	var splitStringNull func(s *byte, count *int32) []string
+	purego.RegisterLibFunc(&splitStringNull, lib, "splitString")
+	sym, err := load.OpenSymbol(lib, "splitString")
+	if err != nil {
+		t.Fatal(err)
+	}
+	splitTyped := purego.NewFunc2[[]string, string, *int32](sym)
+
+	var count int32
+	for name, split := range map[string]func(s string, count *int32) []string{
+		"RegisterFunc": splitString,
+		"NewFunc":      splitTyped,
+	} {
+		if got, want := split("a,bc,", &count), []string{"a", "bc", ""}; !slices.Equal(got, want) {
+			t.Errorf("%s: splitString() = %q, want %q", name, got, want)
+		}
+	}
+	if got := splitStringNull(nil, &count); got != nil {
+		t.Errorf("splitString(NULL) = %q, want nil", got)
+	}
+
+	var freeStrings func(arr uintptr)
+	purego.RegisterLibFunc(&freeStrings, lib, "freeStrings")
+	var freedStrings func() int32
+	purego.RegisterLibFunc(&freedStrings, lib, "freedStrings")
+	splitSym, err := load.OpenSymbol(lib, "splitString")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var splitFreed func(s string, count *int32) []string
+	purego.RegisterStringArrayFunc(&splitFreed, splitSym, purego.StringArrayOptions{CountArg: 1, Free: freeStrings})
+	if got, want := splitFreed("x,y", &count), []string{"x", "y"}; !slices.Equal(got, want) {
+		t.Errorf("splitString() = %q, want %q", got, want)
+	}
+	if got := freedStrings(); got != 1 {
+		t.Errorf("the array was freed %d times, want 1", got)
+	}
+
+	colorsSym, err := load.OpenSymbol(lib, "firstColors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type color string
+	var firstColors func(n int32) []color
+	purego.RegisterStringArrayFunc(&firstColors, colorsSym, purego.StringArrayOptions{CountArg: 0})
+	if got, want := firstColors(2), []color{"red", "green"}; !slices.Equal(got, want) {
+		t.Errorf("firstColors(2) = %q, want %q", got, want)
+	}
+	if got := firstColors(0); got == nil || len(got) != 0 {
+		t.Errorf("firstColors(0) = %#v, want an empty slice", got)
+	}
+}
+
+func TestNullString(t *testing.T) {
+	lib := openStringTest(t)
+
+	ptr := func(s string) *string { return &s }
+	tests := []struct {
+		s    purego.NullString
+		want int32
+	}{
+		{purego.NullString{}, -1},
+		{purego.NullString{String: "ignored"}, -1},
+		{purego.NullString{Valid: true}, 0},
+		{purego.NullString{String: "abc", Valid: true}, 3},
+	}
+
+	var lengthNull func(s purego.NullString) int32
+	purego.RegisterLibFunc(&lengthNull, lib, "stringLength")
+	var lengthPtr func(s *string) int32
+	purego.RegisterLibFunc(&lengthPtr, lib, "stringLength")
+	var lengthAny func(args ...any) int32
+	purego.RegisterLibFunc(&lengthAny, lib, "stringLength")
+	sym, err := load.OpenSymbol(lib, "stringLength")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lengthTyped := purego.NewFunc1[int32, purego.NullString](sym)
+	lengthTypedPtr := purego.NewFunc1[int32, *string](sym)
+	for _, tt := range tests {
+		var p *string
+		if tt.s.Valid {
+			p = ptr(tt.s.String)
+		}
+		if got := lengthNull(tt.s); got != tt.want {
+			t.Errorf("stringLength(%#v) = %d, want %d", tt.s, got, tt.want)
+		}
+		if got := lengthPtr(p); got != tt.want {
+			t.Errorf("stringLength(%#v) with *string = %d, want %d", tt.s, got, tt.want)
+		}
+		if got := lengthAny(tt.s); got != tt.want {
+			t.Errorf("stringLength(%#v) with ...any = %d, want %d", tt.s, got, tt.want)
+		}
+		if got := lengthAny(p); got != tt.want {
+			t.Errorf("stringLength(%#v) with ...any *string = %d, want %d", tt.s, got, tt.want)
+		}
+		if got := lengthTyped(tt.s); got != tt.want {
+			t.Errorf("stringLength(%#v) with NewFunc = %d, want %d", tt.s, got, tt.want)
+		}
+		if got := lengthTypedPtr(p); got != tt.want {
+			t.Errorf("stringLength(%#v) with NewFunc *string = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+
+	var copyNull func(s purego.NullString) purego.NullString
+	purego.RegisterLibFunc(&copyNull, lib, "copyString")
+	var copyPtr func(s *string) *string
+	purego.RegisterLibFunc(&copyPtr, lib, "copyString")
+	copySym, err := load.OpenSymbol(lib, "copyString")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyTyped := purego.NewFunc1[purego.NullString, purego.NullString](copySym)
+	for _, s := range []purego.NullString{{}, {Valid: true}, {String: "abc", Valid: true}} {
+		if got := copyNull(s); got != s {
+			t.Errorf("copyString(%#v) = %#v", s, got)
+		}
+		if got := copyTyped(s); got != s {
+			t.Errorf("copyString(%#v) with NewFunc = %#v", s, got)
+		}
+		var p *string
+		if s.Valid {
+			p = ptr(s.String)
+		}
+		got := copyPtr(p)
+		if (got == nil) != !s.Valid || got != nil && *got != s.String {
+			t.Errorf("copyString(%#v) with *string = %v", s, got)
+		}
+	}
+}
+
+func TestNullStringCallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("string arguments of callbacks are not supported on Windows")
+	}
+	lib := openStringTest(t)
+
+	var callWithString func(cb uintptr, s *string) int32
+	purego.RegisterLibFunc(&callWithString, lib, "callWithString")
+	var got purego.NullString
+	cbNull := purego.NewCallback(func(s purego.NullString) int32 {
+		got = s
+		return 1
+	})
+	var gotPtr *string
+	cbPtr := purego.NewCallback(func(s *string) int32 {
+		gotPtr = s
+		return 2
+	})
+	defer purego.ReleaseCallback(cbNull)
+	defer purego.ReleaseCallback(cbPtr)
+
+	for _, s := range []purego.NullString{{}, {Valid: true}, {String: "abc", Valid: true}} {
+		var p *string
+		if s.Valid {
+			p = &s.String
+		}
+		if callWithString(cbNull, p); got != s {
+			t.Errorf("the callback received %#v, want %#v", got, s)
+		}
+		if callWithString(cbPtr, p); (gotPtr == nil) != !s.Valid || gotPtr != nil && *gotPtr != s.String {
+			t.Errorf("the callback received %v, want %#v", gotPtr, s)
+		}
+	}
+}
+
+func TestOwnedString(t *testing.T) {
+	lib := openStringTest(t)
+
+	var dupBeforeFreeFunc func(s string) purego.OwnedString
+	if err := purego.TryRegisterLibFunc(&dupBeforeFreeFunc, lib, "dupString"); err == nil {
+		t.Error("TryRegisterLibFunc returned no error for an OwnedString result without SetFreeFunc")
+	}
+
+	purego.SetFreeFunc(lib, "freeString")
+	var dupString func(s *string) purego.OwnedString
+	purego.RegisterLibFunc(&dupString, lib, "dupString")
+	var dupStringErrno func(s *string) (purego.OwnedString, error)
+	purego.RegisterLibFunc(&dupStringErrno, lib, "dupString")
+	var dupAny func(args ...any) purego.OwnedString
+	purego.RegisterLibFunc(&dupAny, lib, "dupString")
+	var freedStringCount func() int32
+	purego.RegisterLibFunc(&freedStringCount, lib, "freedStringCount")
+
+	s := "owned"
+	if got := dupString(&s); got != "owned" {
+		t.Errorf("dupString() = %q, want %q", got, "owned")
+	}
+	if got, _ := dupStringErrno(&s); got != "owned" {
+		t.Errorf("dupString() with errno = %q, want %q", got, "owned")
+	}
+	if got := dupAny(&s); got != "owned" {
+		t.Errorf("dupString() with ...any = %q, want %q", got, "owned")
+	}
+	if got := freedStringCount(); got != 3 {
+		t.Errorf("the string was freed %d times, want 3", got)
+	}
+	if got := dupString(nil); got != "" {
+		t.Errorf("dupString(NULL) = %q, want an empty string", got)
+	}
+	if got := freedStringCount(); got != 3 {
+		t.Errorf("NULL was freed, the count is %d, want 3", got)
+	}
+
+	// A plain string result of the same library is not freed.
+	var copyString func(s string) string
+	purego.RegisterLibFunc(&copyString, lib, "copyString")
+	if got := copyString("static"); got != "static" {
+		t.Errorf("copyString() = %q, want %q", got, "static")
+	}
+	if got := freedStringCount(); got != 3 {
+		t.Errorf("a string result was freed, the count is %d, want 3", got)
+	}
+
+	sym, err := load.OpenSymbol(lib, "dupString")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dupFunc func(s string) purego.OwnedString
+	if err := purego.TryRegisterFunc(&dupFunc, sym); err == nil {
+		t.Error("TryRegisterFunc returned no error for an OwnedString result")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("NewFunc1 did not panic for an OwnedString result")
+			}
+		}()
+		purego.NewFunc1[purego.OwnedString, string](sym)
+	}()
+}
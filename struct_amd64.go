@@ -51,46 +51,23 @@ func getStruct(outType reflect.Type, syscall syscallArgs) (v reflect.Value) {
 	switch {
 	case outSize == 0:
 		return reflect.New(outType).Elem()
-	case outSize <= 8:
-		if isAllFloats(outType) {
-			// 2 float32s or 1 float64s are return in the float register
-			return reflect.NewAt(outType, unsafe.Pointer(&struct{ a uintptr }{syscall.f1})).Elem()
-		}
-		// up to 8 bytes is returned in RAX
-		return reflect.NewAt(outType, unsafe.Pointer(&struct{ a uintptr }{syscall.a1})).Elem()
 	case outSize <= 16:
-		r1, r2 := syscall.a1, syscall.a2
-		if isAllFloats(outType) {
-			r1 = syscall.f1
-			r2 = syscall.f2
-		} else {
-			// check first 8 bytes if it's floats
-			hasFirstFloat := false
-			f1 := outType.Field(0).Type
-			if f1.Kind() == reflect.Float64 || f1.Kind() == reflect.Float32 && outType.Field(1).Type.Kind() == reflect.Float32 {
-				r1 = syscall.f1
-				hasFirstFloat = true
-			}
-
-			// find index of the field that starts the second 8 bytes
-			var i int
-			for i = 0; i < outType.NumField(); i++ {
-				if outType.Field(i).Offset == 8 {
-					break
-				}
-			}
-
-			// check last 8 bytes if they are floats
-			f1 = outType.Field(i).Type
-			if f1.Kind() == reflect.Float64 || f1.Kind() == reflect.Float32 && i+1 == outType.NumField() {
-				r2 = syscall.f1
-			} else if hasFirstFloat {
-				// if the first field was a float then that means the second integer field
-				// comes from the first integer register
-				r2 = syscall.a1
+		// Each eightbyte is returned in the next register of its class, RAX and RDX
+		// for INTEGER or XMM0 and XMM1 for SSE, as setStruct does for callbacks.
+		ints := [2]uintptr{syscall.a1, syscall.a2}
+		floats := [2]uintptr{syscall.f1, syscall.f2}
+		var r [2]uintptr
+		var numInts, numFloats int
+		for i := 0; uintptr(i)*8 < outSize; i++ {
+			if classifyEightbyte(outType, uintptr(i)*8, uintptr(i)*8+8) == _SSE {
+				r[i] = floats[numFloats]
+				numFloats++
+			} else {
+				r[i] = ints[numInts]
+				numInts++
 			}
 		}
-		return reflect.NewAt(outType, unsafe.Pointer(&struct{ a, b uintptr }{r1, r2})).Elem()
+		return reflect.NewAt(outType, unsafe.Pointer(&r)).Elem()
 	default:
 		// create struct from the Go pointer created above
 		// weird pointer dereference to circumvent go vet
@@ -98,18 +75,6 @@ func getStruct(outType reflect.Type, syscall syscallArgs) (v reflect.Value) {
 	}
 }
 
-func isAllFloats(ty reflect.Type) bool {
-	for i := 0; i < ty.NumField(); i++ {
-		f := ty.Field(i)
-		switch f.Type.Kind() {
-		case reflect.Float64, reflect.Float32:
-		default:
-			return false
-		}
-	}
-	return true
-}
-
 // https://refspecs.linuxbase.org/elf/x86_64-abi-0.99.pdf
 // https://gitlab.com/x86-psABIs/x86-64-ABI
 // Class determines where the 8 byte value goes.
@@ -138,6 +103,13 @@ func addStruct(v reflect.Value, numInts, numFloats, numStack *int, addInt, addFl
 		placeStack(v, addStack)
 		return keepAlive
 	}
+	if containsUnion(v.Type()) {
+		// The fields of a union overlap, so the eightbytes are copied as they are.
+		if postMerger(v.Type()) || !placeClassified(v, *numInts, *numFloats, addFloat, addInt) {
+			placeStack(v, addStack)
+		}
+		return keepAlive
+	}
 	var (
 		savedNumFloats = *numFloats
 		savedNumInts   = *numInts
@@ -302,6 +274,36 @@ func tryPlaceRegister(v reflect.Value, addFloat func(uintptr), addInt func(uintp
 	return ok
 }
 
+// placeClassified passes the eightbytes of v, which is at most 16 bytes, in the registers of their
+// class as computed by classifyEightbyte. It reports false without placing anything when the
+// registers numInts and numFloats already in use leave no room for all of them.
+func placeClassified(v reflect.Value, numInts, numFloats int, addFloat func(uintptr), addInt func(uintptr)) bool {
+	size := v.Type().Size()
+	var buf [2]uintptr
+	var classes [2]int
+	var needInts, needFloats int
+	for i := 0; uintptr(i)*8 < size; i++ {
+		classes[i] = classifyEightbyte(v.Type(), uintptr(i)*8, uintptr(i)*8+8)
+		if classes[i] == _SSE {
+			needFloats++
+		} else {
+			needInts++
+		}
+	}
+	if numInts+needInts > numOfIntegerRegisters() || numFloats+needFloats > numOfFloatRegisters() {
+		return false
+	}
+	reflect.NewAt(v.Type(), unsafe.Pointer(&buf[0])).Elem().Set(v)
+	for i := 0; uintptr(i)*8 < size; i++ {
+		if classes[i] == _SSE {
+			addFloat(buf[i])
+		} else {
+			addInt(buf[i])
+		}
+	}
+	return true
+}
+
 func placeStack(v reflect.Value, addStack func(uintptr)) {
 	// Copy the struct as a contiguous block of memory in eightbyte (8-byte)
 	// chunks. The x86-64 ABI requires structs passed on the stack to be
@@ -339,7 +341,7 @@ func structFitsInRegisters(val reflect.Value, tempNumInts, tempNumFloats int) (b
 
 // collectStackArgs is not used on amd64.
 func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int,
-	keepAlive []any, addInt, addFloat, addStack func(uintptr),
+	keepAlive []any, addInt, addFloat, addStack func(uintptr), addQuad func(lo, hi uintptr),
 	pNumInts, pNumFloats, pNumStack *int) ([]reflect.Value, []any) {
 	panic("purego: collectStackArgs should not be called on amd64")
 }
@@ -482,6 +484,11 @@ func classifyEightbyte(t reflect.Type, start, end uintptr) int {
 func doClassifyEightbyte(t reflect.Type, base, start, end uintptr) int {
 	switch t.Kind() {
 	case reflect.Struct:
+		if isUnionType(t) {
+			// The class of a union is the merged class of its members.
+			t1, t2 := unionMembers(t)
+			return doClassifyEightbyte(t1, base, start, end) | doClassifyEightbyte(t2, base, start, end)
+		}
 		class := _NO_CLASS
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
@@ -502,7 +509,7 @@ func doClassifyEightbyte(t reflect.Type, base, start, end uintptr) int {
 			return _NO_CLASS
 		}
 		switch t.Kind() {
-		case reflect.Float32, reflect.Float64:
+		case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 			return _SSE
 		default:
 			return _INTEGER
@@ -81,7 +81,7 @@ func structFitsInRegisters(val reflect.Value, tempNumInts, tempNumFloats int) (b
 
 // collectStackArgs is not used on arm.
 func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int,
-	keepAlive []any, addInt, addFloat, addStack func(uintptr),
+	keepAlive []any, addInt, addFloat, addStack func(uintptr), addQuad func(lo, hi uintptr),
 	pNumInts, pNumFloats, pNumStack *int) ([]reflect.Value, []any) {
 	panic("purego: collectStackArgs should not be called on arm")
 }
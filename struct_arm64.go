@@ -81,11 +81,14 @@ func addStruct(v reflect.Value, numInts, numFloats, numStack *int, addInt, addFl
 	if v.Type().Size() == 0 {
 		return keepAlive
 	}
+	if containsUnion(v.Type()) {
+		return addUnionStruct(v, numInts, numFloats, addInt, addFloat, addStack, keepAlive)
+	}
 
 	if hva, hfa, size := isHVA(v.Type()), isHFA(v.Type()), v.Type().Size(); hva || hfa || size <= 16 {
 		// if this doesn't fit entirely in registers then
 		// each element goes onto the stack
-		if hfa && *numFloats+v.NumField() > numOfFloatRegisters() {
+		if _, members := isAllSameFloat(v.Type()); hfa && *numFloats+members > numOfFloatRegisters() {
 			*numFloats = numOfFloatRegisters()
 		} else if hva && *numInts+v.NumField() > numOfIntegerRegisters() {
 			*numInts = numOfIntegerRegisters()
@@ -98,6 +101,48 @@ func addStruct(v reflect.Value, numInts, numFloats, numStack *int, addInt, addFl
 	return keepAlive // the struct was allocated so don't panic
 }
 
+// addUnionStruct passes v, which is or contains a union, from its memory since the fields of a union overlap.
+// A homogeneous floating-point aggregate is passed one element per float register, any other value of at
+// most 16 bytes in integer registers, and a larger one by reference. A value that does not fit in the
+// remaining registers is copied to the stack.
+func addUnionStruct(v reflect.Value, numInts, numFloats *int, addInt, addFloat, addStack func(uintptr), keepAlive []any) []any {
+	size := v.Type().Size()
+	kind, n, hfa := homogeneousFloats(v.Type())
+	if !hfa || n > 4 {
+		if size > 16 {
+			return placeStack(v, keepAlive, addInt)
+		}
+		n = int((size + 7) / 8)
+	}
+	var buf [4]uintptr
+	reflect.NewAt(v.Type(), unsafe.Pointer(&buf[0])).Elem().Set(v)
+	switch {
+	case hfa && n <= 4 && *numFloats+n <= numOfFloatRegisters():
+		for i := range n {
+			if kind == reflect.Float32 {
+				addFloat(uintptr(*(*uint32)(unsafe.Add(unsafe.Pointer(&buf[0]), i*4))))
+			} else {
+				addFloat(buf[i])
+			}
+		}
+	case !(hfa && n <= 4) && *numInts+n <= numOfIntegerRegisters():
+		for i := range n {
+			addInt(buf[i])
+		}
+	default:
+		// The whole value goes on the stack and no later argument uses the remaining registers of its class.
+		if hfa && n <= 4 {
+			*numFloats = numOfFloatRegisters()
+		} else {
+			*numInts = numOfIntegerRegisters()
+		}
+		for i := range (size + 7) / 8 {
+			addStack(buf[i])
+		}
+	}
+	return keepAlive
+}
+
 func placeRegisters(v reflect.Value, addFloat func(uintptr), addInt func(uintptr)) {
 	if isDarwin {
 		placeRegistersDarwin(v, addFloat, addInt)
@@ -236,6 +281,10 @@ func placeStack(v reflect.Value, keepAlive []any, addInt func(uintptr)) []any {
 //
 // [Arm64 Calling Convention]: https://github.com/ARM-software/abi-aa/blob/main/sysvabi64/sysvabi64.rst
 func isHFA(t reflect.Type) bool {
+	if containsUnion(t) {
+		_, n, ok := homogeneousFloats(t)
+		return ok && n <= 4
+	}
 	// round up struct size to nearest 8 see section B.4
 	structSize := roundUpTo8(t.Size())
 	if structSize == 0 || t.NumField() > 4 {
@@ -278,6 +327,9 @@ func isHFA(t reflect.Type) bool {
 //
 // [Arm64 Calling Convention]: https://github.com/ARM-software/abi-aa/blob/main/sysvabi64/sysvabi64.rst
 func isHVA(t reflect.Type) bool {
+	if containsUnion(t) {
+		return false
+	}
 	// round up struct size to nearest 8 see section B.4
 	structSize := roundUpTo8(t.Size())
 	if structSize == 0 || (structSize != 8 && structSize != 16) {
@@ -379,6 +431,9 @@ func shouldBundleStackArgs(v reflect.Value, numInts, numFloats int) bool {
 	if kind != reflect.Struct {
 		return false
 	}
+	if n, ok := homogeneousVectors(v.Type()); ok && n <= 4 {
+		return numFloats+n > numOfFloatRegisters()
+	}
 	hfa := isHFA(v.Type())
 	hva := isHVA(v.Type())
 	size := v.Type().Size()
@@ -388,7 +443,7 @@ func shouldBundleStackArgs(v reflect.Value, numInts, numFloats int) bool {
 	}
 
 	if hfa {
-		need := v.NumField()
+		_, need := isAllSameFloat(v.Type())
 		return numFloats+need > numOfFloatRegisters()
 	}
 
@@ -412,10 +467,26 @@ func structFitsInRegisters(val reflect.Value, tempNumInts, tempNumFloats int) (b
 	hva := isHVA(val.Type())
 	size := val.Type().Size()
 
+	if size == 16 && containsInt128(val.Type()) {
+		// A value aligned to 16 bytes starts at an even-numbered register,
+		// and once it goes on the stack so does every later integer argument.
+		tempNumInts += tempNumInts % 2
+		if tempNumInts+2 <= numOfIntegerRegisters() {
+			return true, tempNumInts + 2, tempNumFloats
+		}
+		return false, numOfIntegerRegisters(), tempNumFloats
+	}
+	if n, ok := homogeneousVectors(val.Type()); ok && n <= 4 {
+		// Vectors do not fit in the vector registers one by one either, see addQuads.
+		if tempNumFloats+n <= numOfFloatRegisters() {
+			return true, tempNumInts, tempNumFloats + n
+		}
+		return false, tempNumInts, numOfFloatRegisters()
+	}
 	if hfa {
 		// HFA: check if elements fit in float registers
-		if tempNumFloats+val.NumField() <= numOfFloatRegisters() {
-			return true, tempNumInts, tempNumFloats + val.NumField()
+		if _, members := isAllSameFloat(val.Type()); tempNumFloats+members <= numOfFloatRegisters() {
+			return true, tempNumInts, tempNumFloats + members
 		}
 	} else if hva {
 		// HVA: check if elements fit in int registers
@@ -436,7 +507,7 @@ func structFitsInRegisters(val reflect.Value, tempNumInts, tempNumFloats int) (b
 // collectStackArgs separates remaining arguments into those that fit in registers vs those that go on stack.
 // It returns the stack arguments and processes register arguments through addValue.
 func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int,
-	keepAlive []any, addInt, addFloat, addStack func(uintptr),
+	keepAlive []any, addInt, addFloat, addStack func(uintptr), addQuad func(lo, hi uintptr),
 	pNumInts, pNumFloats, pNumStack *int) ([]reflect.Value, []any) {
 	if !isDarwin {
 		panic("purego: collectStackArgs should only be called on darwin")
@@ -454,6 +525,10 @@ func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int
 		if val.Kind() == reflect.Struct {
 			// Check if struct still fits in remaining registers
 			fitsInRegister, newNumInts, newNumFloats = structFitsInRegisters(val, tempNumInts, tempNumFloats)
+			if !fitsInRegister {
+				tempNumInts = newNumInts
+				tempNumFloats = newNumFloats
+			}
 		} else {
 			// Primitive argument
 			isFloat := val.Kind() == reflect.Float32 || val.Kind() == reflect.Float64
@@ -472,6 +547,10 @@ func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int
 			// Process through normal register allocation
 			tempNumInts = newNumInts
 			tempNumFloats = newNumFloats
+			if addInt128(val, addInt, addStack, pNumInts, pNumStack) ||
+				addVector(val, addStack, addQuad, pNumFloats, pNumStack) {
+				continue
+			}
 			keepAlive = addValue(val, keepAlive, addInt, addFloat, addStack, pNumInts, pNumFloats, pNumStack)
 		} else {
 			// Convert strings to C strings before bundling
@@ -481,6 +560,15 @@ func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int
 				val = reflect.ValueOf(ptr)
 				args[startIdx+j] = val
 			}
+			if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.String {
+				ptr, pinner := cStringArray(val)
+				keepAlive = append(keepAlive, ptr)
+				if pinner != nil {
+					keepAlive = append(keepAlive, pinner)
+				}
+				val = reflect.ValueOf(ptr)
+				args[startIdx+j] = val
+			}
 			stackArgs = append(stackArgs, val)
 		}
 	}
@@ -515,6 +603,13 @@ func bundleStackArgs(stackArgs []reflect.Value, addStack func(uintptr)) {
 		if val.Kind() == reflect.Struct && valSize >= 8 {
 			valAlign = 8
 		}
+		if containsInt128(val.Type()) && valSize == 16 {
+			// Like __int128, a struct holding one is aligned to 16 bytes.
+			valAlign = 16
+		}
+		if _, ok := homogeneousVectors(val.Type()); ok {
+			valAlign = 16
+		}
 
 		// Add padding field if needed for alignment
 		if currentOffset%uintptr(valAlign) != 0 {
@@ -638,6 +733,10 @@ func readHFAFromRegisters(inType reflect.Type, f *[callbackMaxFrame]uintptr, flo
 		root = root.Field(0).Type
 	}
 	isFloat32 := root.Kind() == reflect.Float32
+	if containsUnion(inType) {
+		kind, _, _ := homogeneousFloats(inType)
+		isFloat32 = kind == reflect.Float32
+	}
 
 	if isFloat32 {
 		// Each float32 is in a separate register (low 32 bits).
@@ -36,7 +36,7 @@ func shouldBundleStackArgs(v reflect.Value, numInts, numFloats int) bool {
 }
 
 func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int,
-	keepAlive []any, addInt, addFloat, addStack func(uintptr),
+	keepAlive []any, addInt, addFloat, addStack func(uintptr), addQuad func(lo, hi uintptr),
 	pNumInts, pNumFloats, pNumStack *int) ([]reflect.Value, []any) {
 	panic("purego: collectStackArgs should not be called on this architecture")
 }
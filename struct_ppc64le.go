@@ -161,7 +161,7 @@ func shouldBundleStackArgs(v reflect.Value, numInts, numFloats int) bool {
 
 // collectStackArgs is not used on ppc64le.
 func collectStackArgs(args []reflect.Value, startIdx int, numInts, numFloats int,
-	keepAlive []any, addInt, addFloat, addStack func(uintptr),
+	keepAlive []any, addInt, addFloat, addStack func(uintptr), addQuad func(lo, hi uintptr),
 	pNumInts, pNumFloats, pNumStack *int) ([]reflect.Value, []any) {
 	panic("purego: collectStackArgs should not be called on ppc64le")
 }
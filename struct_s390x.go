@@ -140,6 +140,7 @@ func collectStackArgs(
 	i, numInts, numFloats int,
 	keepAlive []any,
 	addInt, addFloat, addStack func(uintptr),
+	addQuad func(lo, hi uintptr),
 	numIntsPtr, numFloatsPtr, numStackPtr *int,
 ) ([]reflect.Value, []any) {
 	return nil, keepAlive
@@ -51,6 +51,16 @@ TEXT syscallX(SB), NOSPLIT, $STACK_SIZE
 	MOVQ syscallArgs_f7(R11), X6 // f7
 	MOVQ syscallArgs_f8(R11), X7 // f8
 
+	// Load the upper halves of the vector registers for 128-bit vectors.
+	MOVHPD syscallArgs_f1hi(R11), X0
+	MOVHPD syscallArgs_f2hi(R11), X1
+	MOVHPD syscallArgs_f3hi(R11), X2
+	MOVHPD syscallArgs_f4hi(R11), X3
+	MOVHPD syscallArgs_f5hi(R11), X4
+	MOVHPD syscallArgs_f6hi(R11), X5
+	MOVHPD syscallArgs_f7hi(R11), X6
+	MOVHPD syscallArgs_f8hi(R11), X7
+
 	MOVQ syscallArgs_a1(R11), DI // a1
 	MOVQ syscallArgs_a2(R11), SI // a2
 	MOVQ syscallArgs_a3(R11), DX // a3
@@ -111,7 +121,7 @@ TEXT syscallX(SB), NOSPLIT, $STACK_SIZE
 	MOVQ R12, 192(SP)                // push a31
 	MOVQ syscallArgs_a32(R11), R12
 	MOVQ R12, 200(SP)                // push a32
-	XORL AX, AX                      // vararg: say "no float args"
+	MOVL $8, AX                      // vararg: upper bound of the vector registers used
 
 	MOVQ syscallArgs_fn(R11), R10 // fn
 	CALL R10
@@ -121,6 +131,15 @@ TEXT syscallX(SB), NOSPLIT, $STACK_SIZE
 	MOVQ DX, syscallArgs_a2(DI) // r2
 	MOVQ X0, syscallArgs_f1(DI) // f1
 	MOVQ X1, syscallArgs_f2(DI) // f2
+	MOVHPD X0, syscallArgs_f1hi(DI) // upper half of a 128-bit vector
+
+	// A long double is returned in ST(0), which is the only time the x87 register stack
+	// is not empty after the call. Pop it over f1 and f2 so that the stack stays empty.
+	FSTSW  AX
+	ANDL   $0x3800, AX                // TOP field of the x87 status word
+	JEQ    nox87
+	FMOVXP F0, syscallArgs_f1(DI)
+nox87:
 
 #ifdef GOOS_darwin
 	CALL purego_error(SB)
@@ -51,6 +51,24 @@ TEXT syscallX(SB), NOSPLIT, $0
 	FMOVD syscallArgs_f7(R9), F6 // f7
 	FMOVD syscallArgs_f8(R9), F7 // f8
 
+	// The upper halves of the vector registers hold the rest of a 128-bit long double.
+	MOVD syscallArgs_f1hi(R9), R10
+	VMOV R10, V0.D[1]
+	MOVD syscallArgs_f2hi(R9), R10
+	VMOV R10, V1.D[1]
+	MOVD syscallArgs_f3hi(R9), R10
+	VMOV R10, V2.D[1]
+	MOVD syscallArgs_f4hi(R9), R10
+	VMOV R10, V3.D[1]
+	MOVD syscallArgs_f5hi(R9), R10
+	VMOV R10, V4.D[1]
+	MOVD syscallArgs_f6hi(R9), R10
+	VMOV R10, V5.D[1]
+	MOVD syscallArgs_f7hi(R9), R10
+	VMOV R10, V6.D[1]
+	MOVD syscallArgs_f8hi(R9), R10
+	VMOV R10, V7.D[1]
+
 	MOVD syscallArgs_a1(R9), R0       // a1
 	MOVD syscallArgs_a2(R9), R1       // a2
 	MOVD syscallArgs_a3(R9), R2       // a3
@@ -122,6 +140,14 @@ TEXT syscallX(SB), NOSPLIT, $0
 	FMOVD F1, syscallArgs_f2(R2) // save f1
 	FMOVD F2, syscallArgs_f3(R2) // save f2
 	FMOVD F3, syscallArgs_f4(R2) // save f3
+	VMOV  V0.D[1], R3
+	MOVD  R3, syscallArgs_f1hi(R2)
+	VMOV  V1.D[1], R3
+	MOVD  R3, syscallArgs_f2hi(R2)
+	VMOV  V2.D[1], R3
+	MOVD  R3, syscallArgs_f3hi(R2)
+	VMOV  V3.D[1], R3
+	MOVD  R3, syscallArgs_f4hi(R2)
 
 #ifdef GOOS_darwin
 	BL   purego_error(SB)
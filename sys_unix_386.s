@@ -29,10 +29,10 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	//   0-15: saved callee-saved registers (BX, SI, DI, BP)
 	//   16-19: saved callback index
 	//   20-23: saved return address
-	//   24-35: callbackArgs struct (12 bytes)
-	//   36-291: copy of C arguments (256 bytes for 64 args, matching callbackMaxFrame)
-	// Total: 292 bytes, round up to 304 for alignment
-	SUBL $304, SP
+	//   24-47: callbackArgs struct (24 bytes)
+	//   48-303: copy of C arguments (256 bytes for 64 args, matching callbackMaxFrame)
+	// Total: 304 bytes, round up to 320 for alignment
+	SUBL $320, SP
 
 	// Save callee-saved registers
 	MOVL BX, 0(SP)
@@ -45,149 +45,152 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	MOVL AX, 20(SP)
 
 	// Copy C arguments from original stack location to our frame
-	// Original args start at 304+4(SP) = 308(SP) (past our frame + original return addr)
-	// Copy to our frame at 36(SP)
+	// Original args start at 320+4(SP) = 324(SP) (past our frame + original return addr)
+	// Copy to our frame at 48(SP)
 	// Copy 64 arguments (256 bytes, matching callbackMaxFrame = 64 * ptrSize)
-	MOVL 308(SP), AX
-	MOVL AX, 36(SP)
-	MOVL 312(SP), AX
-	MOVL AX, 40(SP)
-	MOVL 316(SP), AX
-	MOVL AX, 44(SP)
-	MOVL 320(SP), AX
-	MOVL AX, 48(SP)
 	MOVL 324(SP), AX
-	MOVL AX, 52(SP)
+	MOVL AX, 48(SP)
 	MOVL 328(SP), AX
-	MOVL AX, 56(SP)
+	MOVL AX, 52(SP)
 	MOVL 332(SP), AX
-	MOVL AX, 60(SP)
+	MOVL AX, 56(SP)
 	MOVL 336(SP), AX
-	MOVL AX, 64(SP)
+	MOVL AX, 60(SP)
 	MOVL 340(SP), AX
-	MOVL AX, 68(SP)
+	MOVL AX, 64(SP)
 	MOVL 344(SP), AX
-	MOVL AX, 72(SP)
+	MOVL AX, 68(SP)
 	MOVL 348(SP), AX
-	MOVL AX, 76(SP)
+	MOVL AX, 72(SP)
 	MOVL 352(SP), AX
-	MOVL AX, 80(SP)
+	MOVL AX, 76(SP)
 	MOVL 356(SP), AX
-	MOVL AX, 84(SP)
+	MOVL AX, 80(SP)
 	MOVL 360(SP), AX
-	MOVL AX, 88(SP)
+	MOVL AX, 84(SP)
 	MOVL 364(SP), AX
-	MOVL AX, 92(SP)
+	MOVL AX, 88(SP)
 	MOVL 368(SP), AX
-	MOVL AX, 96(SP)
+	MOVL AX, 92(SP)
 	MOVL 372(SP), AX
-	MOVL AX, 100(SP)
+	MOVL AX, 96(SP)
 	MOVL 376(SP), AX
-	MOVL AX, 104(SP)
+	MOVL AX, 100(SP)
 	MOVL 380(SP), AX
-	MOVL AX, 108(SP)
+	MOVL AX, 104(SP)
 	MOVL 384(SP), AX
-	MOVL AX, 112(SP)
+	MOVL AX, 108(SP)
 	MOVL 388(SP), AX
-	MOVL AX, 116(SP)
+	MOVL AX, 112(SP)
 	MOVL 392(SP), AX
-	MOVL AX, 120(SP)
+	MOVL AX, 116(SP)
 	MOVL 396(SP), AX
-	MOVL AX, 124(SP)
+	MOVL AX, 120(SP)
 	MOVL 400(SP), AX
-	MOVL AX, 128(SP)
+	MOVL AX, 124(SP)
 	MOVL 404(SP), AX
-	MOVL AX, 132(SP)
+	MOVL AX, 128(SP)
 	MOVL 408(SP), AX
-	MOVL AX, 136(SP)
+	MOVL AX, 132(SP)
 	MOVL 412(SP), AX
-	MOVL AX, 140(SP)
+	MOVL AX, 136(SP)
 	MOVL 416(SP), AX
-	MOVL AX, 144(SP)
+	MOVL AX, 140(SP)
 	MOVL 420(SP), AX
-	MOVL AX, 148(SP)
+	MOVL AX, 144(SP)
 	MOVL 424(SP), AX
-	MOVL AX, 152(SP)
+	MOVL AX, 148(SP)
 	MOVL 428(SP), AX
-	MOVL AX, 156(SP)
+	MOVL AX, 152(SP)
 	MOVL 432(SP), AX
-	MOVL AX, 160(SP)
+	MOVL AX, 156(SP)
 	MOVL 436(SP), AX
-	MOVL AX, 164(SP)
+	MOVL AX, 160(SP)
 	MOVL 440(SP), AX
-	MOVL AX, 168(SP)
+	MOVL AX, 164(SP)
 	MOVL 444(SP), AX
-	MOVL AX, 172(SP)
+	MOVL AX, 168(SP)
 	MOVL 448(SP), AX
-	MOVL AX, 176(SP)
+	MOVL AX, 172(SP)
 	MOVL 452(SP), AX
-	MOVL AX, 180(SP)
+	MOVL AX, 176(SP)
 	MOVL 456(SP), AX
-	MOVL AX, 184(SP)
+	MOVL AX, 180(SP)
 	MOVL 460(SP), AX
-	MOVL AX, 188(SP)
+	MOVL AX, 184(SP)
 	MOVL 464(SP), AX
-	MOVL AX, 192(SP)
+	MOVL AX, 188(SP)
 	MOVL 468(SP), AX
-	MOVL AX, 196(SP)
+	MOVL AX, 192(SP)
 	MOVL 472(SP), AX
-	MOVL AX, 200(SP)
+	MOVL AX, 196(SP)
 	MOVL 476(SP), AX
-	MOVL AX, 204(SP)
+	MOVL AX, 200(SP)
 	MOVL 480(SP), AX
-	MOVL AX, 208(SP)
+	MOVL AX, 204(SP)
 	MOVL 484(SP), AX
-	MOVL AX, 212(SP)
+	MOVL AX, 208(SP)
 	MOVL 488(SP), AX
-	MOVL AX, 216(SP)
+	MOVL AX, 212(SP)
 	MOVL 492(SP), AX
-	MOVL AX, 220(SP)
+	MOVL AX, 216(SP)
 	MOVL 496(SP), AX
-	MOVL AX, 224(SP)
+	MOVL AX, 220(SP)
 	MOVL 500(SP), AX
-	MOVL AX, 228(SP)
+	MOVL AX, 224(SP)
 	MOVL 504(SP), AX
-	MOVL AX, 232(SP)
+	MOVL AX, 228(SP)
 	MOVL 508(SP), AX
-	MOVL AX, 236(SP)
+	MOVL AX, 232(SP)
 	MOVL 512(SP), AX
-	MOVL AX, 240(SP)
+	MOVL AX, 236(SP)
 	MOVL 516(SP), AX
-	MOVL AX, 244(SP)
+	MOVL AX, 240(SP)
 	MOVL 520(SP), AX
-	MOVL AX, 248(SP)
+	MOVL AX, 244(SP)
 	MOVL 524(SP), AX
-	MOVL AX, 252(SP)
+	MOVL AX, 248(SP)
 	MOVL 528(SP), AX
-	MOVL AX, 256(SP)
+	MOVL AX, 252(SP)
 	MOVL 532(SP), AX
-	MOVL AX, 260(SP)
+	MOVL AX, 256(SP)
 	MOVL 536(SP), AX
-	MOVL AX, 264(SP)
+	MOVL AX, 260(SP)
 	MOVL 540(SP), AX
-	MOVL AX, 268(SP)
+	MOVL AX, 264(SP)
 	MOVL 544(SP), AX
-	MOVL AX, 272(SP)
+	MOVL AX, 268(SP)
 	MOVL 548(SP), AX
-	MOVL AX, 276(SP)
+	MOVL AX, 272(SP)
 	MOVL 552(SP), AX
-	MOVL AX, 280(SP)
+	MOVL AX, 276(SP)
 	MOVL 556(SP), AX
-	MOVL AX, 284(SP)
+	MOVL AX, 280(SP)
 	MOVL 560(SP), AX
+	MOVL AX, 284(SP)
+	MOVL 564(SP), AX
 	MOVL AX, 288(SP)
+	MOVL 568(SP), AX
+	MOVL AX, 292(SP)
+	MOVL 572(SP), AX
+	MOVL AX, 296(SP)
+	MOVL 576(SP), AX
+	MOVL AX, 300(SP)
 
 	// Set up callbackArgs struct at 24(SP)
 	// struct callbackArgs {
-	//     index  uintptr  // offset 0
-	//     args   *byte    // offset 4
-	//     result uintptr  // offset 8
+	//     index  uintptr     // offset 0
+	//     args   *byte       // offset 4
+	//     result [4]uintptr  // offset 8
 	// }
 	MOVL 16(SP), AX // callback index
 	MOVL AX, 24(SP) // callbackArgs.index
-	LEAL 36(SP), AX // pointer to copied arguments
+	LEAL 48(SP), AX // pointer to copied arguments
 	MOVL AX, 28(SP) // callbackArgs.args
-	MOVL $0, 32(SP) // callbackArgs.result = 0
+	MOVL $0, 32(SP) // callbackArgs.result[0] = 0
+	MOVL $0, 36(SP) // callbackArgs.result[1] = 0
+	MOVL $0, 40(SP) // callbackArgs.result[2] = 0
+	MOVL $0, 44(SP) // callbackArgs.result[3] = 0
 
 	// Call crosscall2(fn, frame, 0, ctxt)
 	// crosscall2 expects arguments on stack:
@@ -212,6 +215,20 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	// Get result from callbackArgs.result
 	MOVL 32(SP), AX
 
+	// A float or double result is returned in ST(0). callbackWrap stores its bits
+	// in result[0] and result[1] and its size in bytes in result[2].
+	CMPL 40(SP), $4
+	JNE  notfloat32
+	FMOVF 32(SP), F0
+	JMP  floatdone
+
+notfloat32:
+	CMPL 40(SP), $8
+	JNE  floatdone
+	FMOVD 32(SP), F0
+
+floatdone:
+
 	// Restore callee-saved registers
 	MOVL 0(SP), BX
 	MOVL 4(SP), SI
@@ -220,7 +237,7 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 
 	// Restore return address and clean up
 	MOVL 20(SP), CX // get return address
-	ADDL $304, SP   // remove our frame
+	ADDL $320, SP   // remove our frame
 	MOVL CX, 0(SP)  // put return address back
 
 	RET
@@ -64,8 +64,9 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	// Get result
 	MOVW 56(R13), R0
 
-	// Restore float registers
-	MOVD 64(R13), F0
+	// Restore float registers.
+	// F0 is loaded from result[0] and result[1] for a float or double result.
+	MOVD 56(R13), F0
 	MOVD 72(R13), F1
 	MOVD 80(R13), F2
 	MOVD 88(R13), F3
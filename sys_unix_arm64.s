@@ -73,3 +73,30 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	ADD $(26*8), RSP
 
 	RET
+
+// func flushInstructionCache(addr, size uintptr)
+// The cache lines are walked in steps of 16 bytes, the smallest cache line size allowed by the architecture.
+TEXT ·flushInstructionCache(SB), NOSPLIT, $0-16
+	MOVD addr+0(FP), R0
+	MOVD size+8(FP), R1
+	ADD  R0, R1, R1 // end address
+
+	MOVD R0, R2
+
+dcache:
+	WORD $0xd50b7b22 // DC CVAU, R2
+	ADD  $16, R2
+	CMP  R1, R2
+	BLO  dcache
+	WORD $0xd5033b9f // DSB ISH
+
+	MOVD R0, R2
+
+icache:
+	WORD $0xd50b7522 // IC IVAU, R2
+	ADD  $16, R2
+	CMP  R1, R2
+	BLO  icache
+	WORD $0xd5033b9f // DSB ISH
+	WORD $0xd5033fdf // ISB
+	RET
@@ -70,6 +70,7 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 	// Get callback result.
 	MOVV $16(R3), R13
 	MOVV callbackArgs_result(R13), R4
+	MOVD callbackArgs_result(R13), F0
 
 	// Restore LR and R30
 	MOVV 0(R3), R1
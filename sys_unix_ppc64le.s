@@ -98,8 +98,9 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 
 	BL crosscall2(SB)
 
-	// Get callback result into R3
-	MOVD (CB_ARGS+16)(R1), R3
+	// Get callback result into R3, and into F1 for a float or double result
+	MOVD  (CB_ARGS+16)(R1), R3
+	FMOVD (CB_ARGS+16)(R1), F1
 
 	// Restore R31
 	MOVD SAVE_R31(R1), R31
@@ -68,6 +68,7 @@ TEXT callbackasm1(SB), NOFRAME, $0
 	// Get callback result.
 	ADD $16, SP, X9
 	MOV 16(X9), X10
+	MOVD 16(X9), F10
 
 	// Restore link register and callee-saved X9
 	MOV 8(SP), X9
@@ -99,8 +99,9 @@ TEXT callbackasm1(SB), NOSPLIT|NOFRAME, $0
 
 	BL crosscall2(SB)
 
-	// Get callback result into R2
-	MOVD (CB_ARGS+16)(R15), R2
+	// Get callback result into R2, and into F0 for a float or double result
+	MOVD  (CB_ARGS+16)(R15), R2
+	FMOVD (CB_ARGS+16)(R15), F0
 
 	// Deallocate frame
 	ADD $FRAME_SIZE, R15
@@ -19,6 +19,9 @@ type syscallArgs struct {
 	a16, a17, a18, a19, a20, a21, a22, a23, a24, a25, a26, a27, a28, a29, a30, a31, a32 uintptr
 	f1, f2, f3, f4, f5, f6, f7, f8                                                      uintptr
 	arm64_r8                                                                            uintptr
+	// f1hi to f8hi are the upper halves of the vector registers, which amd64 and arm64 pass
+	// and return for a Vec128, and arm64 also for a 128-bit long double.
+	f1hi, f2hi, f3hi, f4hi, f5hi, f6hi, f7hi, f8hi uintptr
 }
 
 func syscall_SyscallN(fn uintptr, sysargs []uintptr, floats []uintptr, r8 uintptr) *syscallArgs {
@@ -36,6 +39,9 @@ func syscall_SyscallN(fn uintptr, sysargs []uintptr, floats []uintptr, r8 uintpt
 		f1: floats[0], f2: floats[1], f3: floats[2], f4: floats[3],
 		f5: floats[4], f6: floats[5], f7: floats[6], f8: floats[7],
 		arm64_r8: r8,
+		// floats holds the upper halves after the lower halves of all the vector registers.
+		f1hi: floats[8], f2hi: floats[9], f3hi: floats[10], f4hi: floats[11],
+		f5hi: floats[12], f6hi: floats[13], f7hi: floats[14], f8hi: floats[15],
 	}
 	runtime_cgocall(syscallXABI0, unsafe.Pointer(s))
 	return s
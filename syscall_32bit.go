@@ -19,6 +19,8 @@ type syscallArgs struct {
 	a16, a17, a18, a19, a20, a21, a22, a23, a24, a25, a26, a27, a28, a29, a30, a31, a32 uintptr
 	f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14, f15, f16               uintptr
 	arm64_r8                                                                            uintptr
+	// f1hi to f8hi are only used on amd64 and arm64, see syscall.go.
+	f1hi, f2hi, f3hi, f4hi, f5hi, f6hi, f7hi, f8hi uintptr
 }
 
 func syscall_SyscallN(fn uintptr, sysargs []uintptr, floats []uintptr, r8 uintptr) *syscallArgs {
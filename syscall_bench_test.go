@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"unsafe"
 
 	"github.com/ebitengine/purego"
 	"github.com/ebitengine/purego/internal/load"
@@ -177,6 +179,136 @@ func BenchmarkCallingMethods(b *testing.B) {
 	})
 }
 
+// BenchmarkNewFunc measures calling C functions through the typed functions created by the NewFuncN
+// functions, which do not go through reflect.MakeFunc and are expected not to allocate.
+func BenchmarkNewFunc(b *testing.B) {
+	libFileName := filepath.Join(b.TempDir(), "libbenchmark.so")
+	if err := buildSharedLib(b, "CC", libFileName, filepath.Join("testdata", "benchmarktest", "benchmark.c")); err != nil {
+		b.Fatalf("Failed to build C library: %v", err)
+	}
+	libHandle, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		b.Fatalf("Failed to load C library: %v", err)
+	}
+	b.Cleanup(func() {
+		if err := load.CloseLibrary(libHandle); err != nil {
+			b.Fatalf("Failed to close library: %s", err)
+		}
+	})
+	sym := func(name string) uintptr {
+		fn, err := load.OpenSymbol(libHandle, name)
+		if err != nil {
+			b.Fatalf("Failed to load C function %s: %v", name, err)
+		}
+		return fn
+	}
+
+	run := func(b *testing.B, call func() int64, expectedSum int64) {
+		if allocs := testing.AllocsPerRun(100, func() { call() }); allocs != 0 {
+			b.Errorf("got %v allocations per call, want 0", allocs)
+		}
+		b.ReportAllocs()
+		var result int64
+		b.ResetTimer()
+		for range b.N {
+			result = call()
+		}
+		b.StopTimer()
+		if result != expectedSum {
+			b.Fatalf("expected sum %d, got %d", expectedSum, result)
+		}
+	}
+
+	b.Run("1args", func(b *testing.B) {
+		fn := purego.NewFunc1[int64, int64](sym("sum1_c"))
+		run(b, func() int64 { return fn(1) }, 1)
+	})
+	b.Run("2args", func(b *testing.B) {
+		fn := purego.NewFunc2[int64, int64, int64](sym("sum2_c"))
+		run(b, func() int64 { return fn(1, 2) }, 3)
+	})
+	b.Run("3args", func(b *testing.B) {
+		fn := purego.NewFunc3[int64, int64, int64, int64](sym("sum3_c"))
+		run(b, func() int64 { return fn(1, 2, 3) }, 6)
+	})
+	b.Run("5args", func(b *testing.B) {
+		fn := purego.NewFunc5[int64, int64, int64, int64, int64, int64](sym("sum5_c"))
+		run(b, func() int64 { return fn(1, 2, 3, 4, 5) }, 15)
+	})
+}
+
+// BenchmarkCallbackParallel measures C calling a Go callback from many goroutines at once.
+// Every goroutine calls the same callback so that any lock taken when dispatching it is contended.
+// A callback whose arguments and result are integers, bools, pointers or floating-point numbers
+// is expected not to allocate.
+func BenchmarkCallbackParallel(b *testing.B) {
+	libFileName := filepath.Join(b.TempDir(), "libbenchmark.so")
+	if err := buildSharedLib(b, "CC", libFileName, filepath.Join("testdata", "benchmarktest", "benchmark.c")); err != nil {
+		b.Fatalf("Failed to build C library: %v", err)
+	}
+	libHandle, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		b.Fatalf("Failed to load C library: %v", err)
+	}
+	b.Cleanup(func() {
+		if err := load.CloseLibrary(libHandle); err != nil {
+			b.Fatalf("Failed to close library: %s", err)
+		}
+	})
+	sym := func(name string) uintptr {
+		fn, err := load.OpenSymbol(libHandle, name)
+		if err != nil {
+			b.Fatalf("Failed to load C function %s: %v", name, err)
+		}
+		return fn
+	}
+
+	run := func(b *testing.B, call func() int64, expectedSum int64) {
+		// Only amd64 and arm64 call a callback without reflect.Value.Call.
+		if runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64" {
+			if allocs := testing.AllocsPerRun(100, func() { call() }); allocs > 0 {
+				b.Errorf("callback made %v allocations per call, want 0", allocs)
+			}
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if result := call(); result != expectedSum {
+					b.Errorf("expected sum %d, got %d", expectedSum, result)
+					return
+				}
+			}
+		})
+	}
+
+	b.Run("1args", func(b *testing.B) {
+		cb := purego.NewCallback(goSum1)
+		fn := purego.NewFunc2[int64, uintptr, int64](sym("call_callback1"))
+		run(b, func() int64 { return fn(cb, 1) }, 1)
+	})
+	b.Run("2args", func(b *testing.B) {
+		cb := purego.NewCallback(goSum2)
+		fn := purego.NewFunc3[int64, uintptr, int64, int64](sym("call_callback2"))
+		run(b, func() int64 { return fn(cb, 1, 2) }, 3)
+	})
+	b.Run("5args", func(b *testing.B) {
+		cb := purego.NewCallback(goSum5)
+		fn := purego.NewFunc6[int64, uintptr, int64, int64, int64, int64, int64](sym("call_callback5"))
+		run(b, func() int64 { return fn(cb, 1, 2, 3, 4, 5) }, 15)
+	})
+	b.Run("compare", func(b *testing.B) {
+		cb := purego.NewCallback(func(a, b unsafe.Pointer) int32 {
+			return int32(*(*int64)(a) - *(*int64)(b))
+		})
+		fn := purego.NewFunc3[int32, uintptr, unsafe.Pointer, unsafe.Pointer](sym("call_compare"))
+		x, y := new(int64), new(int64)
+		*x, *y = 1, 3
+		run(b, func() int64 { return int64(fn(cb, unsafe.Pointer(x), unsafe.Pointer(y))) }, -2)
+	})
+}
+
 // makeRegisterFunc creates a function pointer of the appropriate signature
 func makeRegisterFunc(n int) any {
 	switch n {
@@ -15,6 +15,22 @@ func NewCallback(_ any) uintptr {
 	panic("purego: NewCallback on Linux is only supported on 386/amd64/arm64/arm/loong64/ppc64le/riscv64/s390x")
 }
 
+func ReleaseCallback(_ uintptr) {
+	panic("purego: ReleaseCallback on Linux is only supported on 386/amd64/arm64/arm/loong64/ppc64le/riscv64/s390x")
+}
+
+func SetCallbackPanicHandler(_ func(v any) uintptr) {
+	panic("purego: SetCallbackPanicHandler on Linux is only supported on 386/amd64/arm64/arm/loong64/ppc64le/riscv64/s390x")
+}
+
+func compileClosure(_ any, _ any) (ptr, index uintptr) {
+	panic("purego: NewClosure on Linux is only supported on amd64/arm64")
+}
+
+func releaseClosure(_ uintptr) {
+	panic("purego: NewClosure on Linux is only supported on amd64/arm64")
+}
+
 func syscall_syscallN(fn uintptr, args ...uintptr) (r1, r2, err uintptr) {
 	panic("purego: syscall_syscallN is only supported on windows")
 }
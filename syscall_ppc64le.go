@@ -17,6 +17,8 @@ type syscallArgs struct {
 	fn, a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15 uintptr
 	f1, f2, f3, f4, f5, f6, f7, f8                                       uintptr
 	arm64_r8                                                             uintptr
+	// f1hi to f8hi are only used on amd64 and arm64, see syscall.go.
+	f1hi, f2hi, f3hi, f4hi, f5hi, f6hi, f7hi, f8hi uintptr
 }
 
 func syscall_SyscallN(fn uintptr, sysargs []uintptr, floats []uintptr, r8 uintptr) *syscallArgs {
@@ -10,7 +10,10 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"unsafe"
+
+	"github.com/ebitengine/purego/internal/strings"
 )
 
 var syscallXABI0 uintptr
@@ -21,10 +24,15 @@ func syscall_syscallN(fn uintptr, args ...uintptr) (r1, r2, err uintptr) {
 
 // NewCallback converts a Go function to a function pointer conforming to the C calling convention.
 // This is useful when interoperating with C code requiring callbacks. The argument is expected to be a
-// function with zero or one uintptr-sized result. The function must not have arguments with size larger than the size
-// of uintptr. Only a limited number of callbacks may be created in a single Go process, and any memory allocated
-// for these callbacks is never released. At least 2000 callbacks can always be created. Although this function
-// provides similar functionality to windows.NewCallback it is distinct.
+// function with zero or one result, which is an integer, bool, pointer, float32 or float64, or on darwin
+// and linux on amd64 and arm64 also a struct, a complex number, an [Int128] or a [Uint128]. The arguments
+// may be of the same types. A string argument receives a copy of a NUL-terminated char*, and a [NullString] or *string
+// argument also reports whether the char* is NULL. A slice argument []T receives two C arguments, a T*
+// followed by the number of elements, and refers to C memory which is only valid until the callback
+// returns. Only a limited number of callbacks may be created in a single Go process, and the slot of
+// a callback is only reused after it is passed to [ReleaseCallback]. At least 2000 callbacks can always
+// be created. [NewClosure] is not subject to this limit. Although this function provides similar
+// functionality to windows.NewCallback it is distinct.
 func NewCallback(fn any) uintptr {
 	ty := reflect.TypeOf(fn)
 	for i := 0; i < ty.NumIn(); i++ {
@@ -43,13 +51,52 @@ func NewCallback(fn any) uintptr {
 // only increase this if you have added more to the callbackasm function
 const maxCB = 2000
 
+// cbs holds the callbacks created by NewCallback. lock is only taken to create or release
+// a callback, so calling one does not contend with other calls.
 var cbs struct {
 	lock  sync.Mutex
-	numFn int                  // the number of functions currently in cbs.funcs
-	funcs [maxCB]reflect.Value // the saved callbacks
+	numFn int                             // the number of slots of cbs.funcs that have ever been used
+	funcs [maxCB]atomic.Pointer[callback] // the saved callbacks
+	free  []int                           // the indices of released slots below numFn
+}
+
+// callback is a Go function that C code can call.
+type callback struct {
+	fn       reflect.Value
+	userData reflect.Value // the first argument of a closure, or the zero Value for NewCallback
+	plan     *callbackPlan
+	direct   directFunc // fn called without reflect.Value.Call if plan.directRegs is not nil
+}
+
+func newCallback(fn, userData reflect.Value, first int) *callback {
+	cb := &callback{fn: fn, userData: userData, plan: callbackPlanFor(fn.Type(), first)}
+	if cb.plan.directRegs != nil {
+		cb.direct = newDirectFunc(fn)
+	}
+	return cb
 }
 
 func compileCallback(fn any) uintptr {
+	cb := newCallback(callbackFunc(fn, 0), reflect.Value{}, 0)
+	cbs.lock.Lock()
+	defer cbs.lock.Unlock()
+	if n := len(cbs.free); n > 0 {
+		i := cbs.free[n-1]
+		cbs.free = cbs.free[:n-1]
+		cbs.funcs[i].Store(cb)
+		return callbackasmAddr(i)
+	}
+	if cbs.numFn >= maxCB {
+		panic("purego: the maximum number of callbacks has been reached")
+	}
+	cbs.funcs[cbs.numFn].Store(cb)
+	cbs.numFn++
+	return callbackasmAddr(cbs.numFn - 1)
+}
+
+// callbackFunc returns fn as a reflect.Value after checking that its arguments,
+// starting at index first, and its result can be passed to and from C.
+func callbackFunc(fn any, first int) reflect.Value {
 	val := reflect.ValueOf(fn)
 	if val.Kind() != reflect.Func {
 		panic("purego: the type must be a function but was not")
@@ -58,19 +105,37 @@ func compileCallback(fn any) uintptr {
 		panic("purego: function must not be nil")
 	}
 	ty := val.Type()
-	for i := 0; i < ty.NumIn(); i++ {
+	for i := first; i < ty.NumIn(); i++ {
 		in := ty.In(i)
 		switch in.Kind() {
 		case reflect.Struct:
-			if i == 0 && in.AssignableTo(reflect.TypeFor[CDecl]()) {
+			if i == first && in.AssignableTo(reflect.TypeFor[CDecl]()) {
+				continue
+			}
+			if isPinnedType(in) {
+				panic("purego: Pinned is not supported as a callback argument")
+			}
+			if isNullStringType(in) {
 				continue
 			}
+			if containsLongDouble(in) {
+				panic("purego: LongDouble is not supported in callbacks")
+			}
+			if containsVector(in) {
+				panic("purego: Vec128 is not supported in callbacks")
+			}
+			if containsInt128(in) && !isInt128Type(in) {
+				panic("purego: a struct holding an Int128 or a Uint128 is not supported as a callback argument")
+			}
 			ensureCallbackStructSupported()
 			checkStructFieldsSupported(in)
 			continue
-		case reflect.Interface, reflect.Func, reflect.Slice,
-			reflect.Chan, reflect.Complex64, reflect.Complex128,
-			reflect.String, reflect.Map, reflect.Invalid:
+		case reflect.Complex64, reflect.Complex128:
+			ensureCallbackStructSupported()
+			ensureComplexSupported()
+			continue
+		case reflect.Interface, reflect.Func,
+			reflect.Chan, reflect.Map, reflect.Invalid:
 			panic("purego: unsupported argument type: " + in.Kind().String())
 		}
 	}
@@ -79,26 +144,51 @@ output:
 	case ty.NumOut() == 1:
 		switch ty.Out(0).Kind() {
 		case reflect.Struct:
+			if isPinnedType(ty.Out(0)) || isNullStringType(ty.Out(0)) {
+				break
+			}
+			if containsLongDouble(ty.Out(0)) {
+				panic("purego: LongDouble is not supported in callbacks")
+			}
+			if containsVector(ty.Out(0)) {
+				panic("purego: Vec128 is not supported in callbacks")
+			}
 			ensureCallbackStructSupported()
 			checkStructFieldsSupported(ty.Out(0))
 			break output
+		case reflect.Complex64, reflect.Complex128:
+			ensureCallbackStructSupported()
+			ensureComplexSupported()
+			break output
 		case reflect.Pointer, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-			reflect.Bool, reflect.UnsafePointer:
+			reflect.Bool, reflect.UnsafePointer, reflect.Float32, reflect.Float64:
 			break output
 		}
 		panic("purego: unsupported return type: " + ty.String())
 	case ty.NumOut() > 1:
 		panic("purego: callbacks can only have one return")
 	}
+	return val
+}
+
+// ReleaseCallback releases the callback ptr returned by [NewCallback] so that its slot
+// can be reused by a later call to NewCallback. The caller must ensure that C code
+// no longer calls ptr, since it may refer to a different Go function afterwards.
+// ReleaseCallback panics if ptr is not a callback that is currently in use.
+func ReleaseCallback(ptr uintptr) {
+	size := uintptr(callbackasmEntrySize())
+	if ptr < callbackasmABI0 || (ptr-callbackasmABI0)%size != 0 {
+		panic("purego: ReleaseCallback called with a pointer that was not returned by NewCallback")
+	}
+	i := (ptr - callbackasmABI0) / size
 	cbs.lock.Lock()
 	defer cbs.lock.Unlock()
-	if cbs.numFn >= maxCB {
-		panic("purego: the maximum number of callbacks has been reached")
+	if i >= uintptr(cbs.numFn) || cbs.funcs[i].Load() == nil {
+		panic("purego: ReleaseCallback called with a callback that is not in use")
 	}
-	cbs.funcs[cbs.numFn] = val
-	cbs.numFn++
-	return callbackasmAddr(cbs.numFn - 1)
+	cbs.funcs[i].Store(nil)
+	cbs.free = append(cbs.free, int(i))
 }
 
 const ptrSize = unsafe.Sizeof((*int)(nil))
@@ -120,155 +210,650 @@ var callbackWrap_call = callbackWrap
 // callbackWrap is called by assembly code which determines which Go function to call.
 // This function takes the arguments and passes them to the Go function and returns the result.
 func callbackWrap(a *callbackArgs) {
-	cbs.lock.Lock()
-	fn := cbs.funcs[a.index]
-	cbs.lock.Unlock()
-	fnType := fn.Type()
-	args := make([]reflect.Value, fnType.NumIn())
-	frame := (*[callbackMaxFrame]uintptr)(a.args)
-	// stackFrame points to stack-passed arguments. On most architectures this is
-	// contiguous with frame (after register args), but on ppc64le it's separate.
-	var stackFrame *[callbackMaxFrame]uintptr
-	var intFrame *[callbackMaxFrame]uintptr
+	returned := false
+	defer func() {
+		if returned {
+			return
+		}
+		// recover returns nil only during runtime.Goexit since panic(nil) panics with *runtime.PanicNilError.
+		v := recover()
+		if v == nil {
+			// Goexit cannot be stopped, and the goroutine cannot exit while C frames that expect
+			// the callback to return are on its stack.
+			runtime_throw("purego: runtime.Goexit called in a callback")
+		}
+		h := callbackPanicHandler.Load()
+		if h == nil {
+			// Without a handler the panic continues through the C frames.
+			panic(v)
+		}
+		r := (*h)(v)
+		cb := lookupCallback(a.index)
+		if cb == nil {
+			// The callback was released, so its result type is unknown.
+			a.result[0] = r
+			return
+		}
+		// The result is stored like a returned one, which keeps the pointer to the memory
+		// of a struct that is returned in memory.
+		if plan := cb.plan; plan.setResult != nil {
+			plan.setResult(a, panicResult(plan.outType, r))
+		}
+	}()
+	callCallback(a)
+	returned = true
+}
+
+// panicResult returns the result r of the callback panic handler as a value of the result type ty
+// of a callback. A floating-point number, a complex number or a struct is zero.
+func panicResult(ty reflect.Type, r uintptr) reflect.Value {
+	switch ty.Kind() {
+	case reflect.Bool:
+		return reflect.ValueOf(r != 0)
+	case reflect.Pointer, reflect.UnsafePointer:
+		return reflect.NewAt(ty, unsafe.Pointer(&r)).Elem()
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Struct:
+		return reflect.Zero(ty)
+	}
+	return reflect.ValueOf(r).Convert(ty)
+}
+
+// lookupCallback returns the callback with the given index, or nil if it was released.
+func lookupCallback(index uintptr) *callback {
+	if index < maxCB {
+		return cbs.funcs[index].Load()
+	}
+	return lookupClosure(index)
+}
+
+// callCallback calls the Go function of the callback described by a and stores its result in a.
+func callCallback(a *callbackArgs) {
+	cb := lookupCallback(a.index)
+	if cb == nil {
+		panic("purego: callback called after it was released")
+	}
+	plan := cb.plan
+	f := callbackFrames{args: a.args, frame: (*[callbackMaxFrame]uintptr)(a.args)}
 	if sf := a.stackFrame(); sf != nil {
-		// Only ppc64le uses separate stackArgs pointer due to NOSPLIT constraints
-		stackFrame = (*[callbackMaxFrame]uintptr)(sf)
+		// Only ppc64le and s390x use a separate stackArgs pointer due to NOSPLIT constraints
+		f.stackFrame = (*[callbackMaxFrame]uintptr)(sf)
 	}
 	if intf := a.intFrame(); intf != nil {
-		intFrame = (*[callbackMaxFrame]uintptr)(intf)
+		f.intFrame = (*[callbackMaxFrame]uintptr)(intf)
+	}
+	if cb.direct != nil {
+		callDirect(a, cb, &f)
+		return
+	}
+	argsPtr := plan.args.Get().(*[]reflect.Value)
+	args := *argsPtr
+	if cb.userData.IsValid() {
+		args[0] = cb.userData
+	}
+	for i := range plan.params {
+		p := &plan.params[i]
+		c := p.cursor
+		args[plan.first+i] = f.decode(p.kind, &c, p.typ)
+	}
+	ret := cb.fn.Call(args)
+	// Drop the references to the arguments before args is reused by another call.
+	clear(args)
+	plan.args.Put(argsPtr)
+	if plan.setResult != nil {
+		plan.setResult(a, ret[0])
+	}
+}
+
+// directFunc is the shape through which a callback calls a Go function whose arguments and result are
+// integers, bools, pointers or floating-point numbers that fit in the registers of the Go internal ABI.
+// That ABI assigns the integer and the floating-point arguments to their registers in order independently
+// of each other and a function ignores the registers that it does not declare, so calling the function
+// as a directFunc with each argument in its register calls it without reflect.Value.Call, which allocates.
+// The results are read from the first integer and floating-point result registers. It is only used on
+// amd64 and arm64, where the internal ABI has enough registers for directFunc and holds a float32 in the
+// low half of a floating-point register.
+type directFunc func(i0, i1, i2, i3, i4, i5, i6, i7, i8 uintptr,
+	f0, f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14 float64) (uintptr, float64)
+
+const (
+	numDirectInts   = 9
+	numDirectFloats = 15
+)
+
+// newDirectFunc returns fn as a directFunc. fn must be a func.
+func newDirectFunc(fn reflect.Value) directFunc {
+	x := fn.Interface()
+	// An interface holding a func holds the pointer to the func value like a directFunc does.
+	return *(*directFunc)(unsafe.Add(unsafe.Pointer(&x), ptrSize))
+}
+
+// directRegs assigns the arguments of a function of type ty to the registers of a directFunc, where the
+// floating-point registers follow the integer ones. It reports false if ty cannot be called as a directFunc.
+func directRegs(ty reflect.Type) ([]int, bool) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		return nil, false
+	}
+	if ty.NumOut() == 1 && !isDirectKind(ty.Out(0)) {
+		return nil, false
+	}
+	regs := make([]int, ty.NumIn())
+	var ints, floats int
+	for i := range regs {
+		in := ty.In(i)
+		switch {
+		case !isDirectKind(in):
+			return nil, false
+		case in.Kind() == reflect.Float32 || in.Kind() == reflect.Float64:
+			regs[i] = numDirectInts + floats
+			floats++
+		default:
+			regs[i] = ints
+			ints++
+		}
+	}
+	if ints > numDirectInts || floats > numDirectFloats {
+		return nil, false
+	}
+	return regs, true
+}
+
+// isDirectKind reports whether a value of type t is held in a single register by the Go internal ABI
+// and passed to and from C as is.
+func isDirectKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Bool, reflect.UnsafePointer, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		// A *string is converted from a C string.
+		return !isStringPointerType(t)
+	}
+	return false
+}
+
+// directWord returns v as the contents of the register that holds it in the Go internal ABI.
+func directWord(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Float32:
+		return uint64(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		return math.Float64bits(v.Float())
+	default:
+		return uint64(uintptr(v.UnsafePointer()))
+	}
+}
+
+// callDirect calls the Go function of cb through its directFunc with the arguments in f and stores its result in a.
+func callDirect(a *callbackArgs, cb *callback, f *callbackFrames) {
+	plan := cb.plan
+	var regs [numDirectInts + numDirectFloats]uint64
+	if cb.userData.IsValid() {
+		regs[plan.directRegs[0]] = directWord(cb.userData)
+	}
+	for i := range plan.params {
+		p := &plan.params[i]
+		c := p.cursor
+		regs[plan.directRegs[plan.first+i]] = directWord(f.decode(p.kind, &c, p.typ))
+	}
+	fl := func(i int) float64 {
+		return math.Float64frombits(regs[numDirectInts+i])
 	}
+	r, fr := cb.direct(uintptr(regs[0]), uintptr(regs[1]), uintptr(regs[2]), uintptr(regs[3]), uintptr(regs[4]),
+		uintptr(regs[5]), uintptr(regs[6]), uintptr(regs[7]), uintptr(regs[8]),
+		fl(0), fl(1), fl(2), fl(3), fl(4), fl(5), fl(6), fl(7), fl(8), fl(9), fl(10), fl(11), fl(12), fl(13), fl(14))
+	// The bits of a register above a result that is smaller than it are undefined.
+	switch plan.out {
+	case reflect.Invalid:
+	case reflect.Int8:
+		a.result[0] = uintptr(int8(r))
+	case reflect.Int16:
+		a.result[0] = uintptr(int16(r))
+	case reflect.Int32:
+		a.result[0] = uintptr(int32(r))
+	case reflect.Uint8:
+		a.result[0] = uintptr(uint8(r))
+	case reflect.Uint16:
+		a.result[0] = uintptr(uint16(r))
+	case reflect.Uint32:
+		a.result[0] = uintptr(uint32(r))
+	case reflect.Bool:
+		a.result[0] = uintptr(uint8(r) & 1)
+	case reflect.Float32:
+		setFloatBits(a, float64(math.Float32frombits(uint32(math.Float64bits(fr)))), true)
+	case reflect.Float64:
+		setFloatBits(a, fr, false)
+	default:
+		a.result[0] = r
+	}
+}
+
+// callbackPlan describes how to call a Go function with the arguments passed by C.
+// It only depends on the signature of the function, so it is computed once when
+// a callback is created and shared by every callback with the same signature.
+type callbackPlan struct {
+	// first is the index of the first argument passed by C.
+	// A closure receives its user data before the C arguments.
+	first  int
+	params []callbackParam
+	// setResult stores the result of the function in callbackArgs. It is nil if there is no result.
+	setResult func(a *callbackArgs, v reflect.Value)
+	// args holds *[]reflect.Value to pass to reflect.Value.Call so that a call does not allocate them.
+	args sync.Pool
+	// directRegs holds the register of each argument of a function that is called as a directFunc,
+	// including the user data of a closure, or is nil if the function is called with reflect.Value.Call.
+	directRegs []int
+	// outType is the type of the result, or nil if there is none.
+	outType reflect.Type
+	// out is the kind of the result, or reflect.Invalid if there is none.
+	out reflect.Kind
+}
+
+// callbackParam is an argument of a callback that is passed by C.
+type callbackParam struct {
+	typ    reflect.Type
+	kind   callbackArgKind
+	cursor callbackCursor // where the argument begins in the argument block
+}
+
+// callbackArgKind selects how an argument is read from the argument block.
+type callbackArgKind uint8
+
+const (
+	callbackArgInt callbackArgKind = iota
+	callbackArgFloat
+	callbackArgStruct
+	callbackArgComplex // complex numbers and structs that contain them, read as the struct of their parts
+	callbackArgString
+	callbackArgNullString // NullString and *string, received as a char* that may be NULL
+	callbackArgSlice
+	callbackArgZero // CDecl and empty structs, which C does not pass
+	callbackArgInt128
+)
+
+// callbackCursor is a position in the argument block of a callback.
+type callbackCursor struct {
 	// floatsN and intsN track the number of register slots used, not argument count.
 	// This distinction matters on ARM32 where float64 uses 2 slots (32-bit registers).
-	var floatsN int
-	var intsN int
-	// On amd64/loong64/ppc64le/riscv64/s390x, when returning a struct larger than
+	floatsN int
+	intsN   int
+	// stackSlot is the index into frame (or stackFrame) of the current stack element.
+	// When stackFrame is nil, stack begins after float and integer registers in frame.
+	// When stackFrame is not nil (ppc64le, s390x), stackSlot indexes into stackFrame starting at 0.
+	stackSlot int
+	// stackByteOffset tracks the byte offset within the stack area for Darwin ARM64
+	// tight packing. On Darwin ARM64, C passes small types packed on the stack.
+	stackByteOffset uintptr
+}
+
+// callbackFrames are the areas of the argument block of a single call to a callback.
+type callbackFrames struct {
+	args  unsafe.Pointer
+	frame *[callbackMaxFrame]uintptr
+	// stackFrame points to stack-passed arguments. On most architectures this is
+	// contiguous with frame (after register args), but on ppc64le and s390x it's separate.
+	stackFrame *[callbackMaxFrame]uintptr
+	intFrame   *[callbackMaxFrame]uintptr
+}
+
+type callbackPlanKey struct {
+	typ   reflect.Type
+	first int
+}
+
+// callbackPlans caches a *callbackPlan for each callbackPlanKey.
+var callbackPlans sync.Map
+
+func callbackPlanFor(ty reflect.Type, first int) *callbackPlan {
+	key := callbackPlanKey{typ: ty, first: first}
+	if p, ok := callbackPlans.Load(key); ok {
+		return p.(*callbackPlan)
+	}
+	p, _ := callbackPlans.LoadOrStore(key, newCallbackPlan(ty, first))
+	return p.(*callbackPlan)
+}
+
+func newCallbackPlan(ty reflect.Type, first int) *callbackPlan {
+	numIn := ty.NumIn()
+	plan := &callbackPlan{first: first}
+	plan.args.New = func() any {
+		args := make([]reflect.Value, numIn)
+		return &args
+	}
+
+	hasStackFrame := runtime.GOARCH == "ppc64le" || runtime.GOARCH == "s390x"
+	var c callbackCursor
+	if !hasStackFrame {
+		c.stackSlot = numOfIntegerRegisters() + numOfFloatRegisters()
+	}
+	// On amd64/loong64/riscv64/s390x, when returning a struct larger than
 	// maxRegAllocStructSize, the caller passes a hidden pointer in the first integer
 	// register. Skip it to avoid misreading it as the first function argument.
 	if (runtime.GOARCH == "amd64" || runtime.GOARCH == "loong64" || runtime.GOARCH == "riscv64" || runtime.GOARCH == "s390x") &&
-		fnType.NumOut() == 1 && fnType.Out(0).Kind() == reflect.Struct &&
-		fnType.Out(0).Size() > maxRegAllocStructSize {
-		intsN = 1
+		ty.NumOut() == 1 && ty.Out(0).Kind() == reflect.Struct &&
+		ty.Out(0).Size() > maxRegAllocStructSize {
+		c.intsN = 1
 	}
-	// stackSlot points to the index into frame (or stackFrame) of the current stack element.
-	// When stackFrame is nil, stack begins after float and integer registers in frame.
-	// When stackFrame is not nil (ppc64le), stackSlot indexes into stackFrame starting at 0.
-	stackSlot := numOfIntegerRegisters() + numOfFloatRegisters()
-	if stackFrame != nil {
-		// ppc64le: stackArgs is a separate pointer, indices start at 0
-		stackSlot = 0
+
+	// Where an argument begins only depends on the types of the arguments before it,
+	// so reading every argument from a zeroed argument block finds all the positions.
+	var zero [callbackMaxFrame]uintptr
+	f := callbackFrames{args: unsafe.Pointer(&zero), frame: &zero}
+	if hasStackFrame {
+		f.stackFrame = &zero
 	}
-	// stackByteOffset tracks the byte offset within the stack area for Darwin ARM64
-	// tight packing. On Darwin ARM64, C passes small types packed on the stack.
-	stackByteOffset := uintptr(0)
-	for i := range args {
-		// slots is the number of pointer-sized slots the argument takes
-		var slots int
-		inType := fnType.In(i)
-		switch inType.Kind() {
+	for i := first; i < numIn; i++ {
+		p := callbackParam{typ: ty.In(i), cursor: c}
+		switch p.typ.Kind() {
 		case reflect.Float32, reflect.Float64:
-			slots = int((fnType.In(i).Size() + ptrSize - 1) / ptrSize)
-			if floatsN+slots > numOfFloatRegisters() {
-				if isDarwin && runtime.GOARCH == "arm64" {
-					// Darwin ARM64: read from packed stack with proper alignment
-					args[i] = callbackArgFromStack(a.args, stackSlot, &stackByteOffset, inType)
-				} else if stackFrame != nil {
-					// ppc64le/s390x: stack args are in separate stackFrame
-					switch runtime.GOARCH {
-					case "ppc64le":
-						args[i] = callbackFloatFromDoubleSlot(unsafe.Pointer(&stackFrame[stackSlot]), inType)
-					case "s390x":
-						// s390x big-endian: sub-8-byte values are right-justified
-						args[i] = callbackArgFromSlotBigEndian(unsafe.Pointer(&stackFrame[stackSlot]), inType)
-					default:
-						args[i] = reflect.NewAt(inType, unsafe.Pointer(&stackFrame[stackSlot])).Elem()
-					}
-					stackSlot += slots
-				} else {
-					args[i] = reflect.NewAt(inType, unsafe.Pointer(&frame[stackSlot])).Elem()
-					stackSlot += slots
-				}
-			} else {
-				switch runtime.GOARCH {
-				case "ppc64le":
-					args[i] = callbackFloatFromDoubleSlot(unsafe.Pointer(&frame[floatsN]), inType)
-				case "s390x":
-					// s390x big-endian: float32 is right-justified in 8-byte FPR slot
-					args[i] = callbackArgFromSlotBigEndian(unsafe.Pointer(&frame[floatsN]), inType)
-				default:
-					args[i] = reflect.NewAt(inType, unsafe.Pointer(&frame[floatsN])).Elem()
-				}
-			}
-			floatsN += slots
+			p.kind = callbackArgFloat
 		case reflect.Struct:
-			if i == 0 && inType.AssignableTo(reflect.TypeFor[CDecl]()) {
-				args[i] = reflect.Zero(inType)
-				continue
+			p.kind = callbackArgStruct
+			if isNullStringType(p.typ) {
+				p.kind = callbackArgNullString
 			}
-			if inType.Size() == 0 {
-				args[i] = reflect.New(inType).Elem()
-				continue
+			if cLayoutType(p.typ) != p.typ {
+				p.kind = callbackArgComplex
 			}
-			args[i] = getCallbackStruct(inType, a.args, &floatsN, &intsN, &stackSlot, &stackByteOffset)
-			continue
+			if isInt128Type(p.typ) {
+				p.kind = callbackArgInt128
+			}
+			if (i == first && p.typ.AssignableTo(reflect.TypeFor[CDecl]())) || p.typ.Size() == 0 {
+				p.kind = callbackArgZero
+			}
+		case reflect.Complex64, reflect.Complex128:
+			p.kind = callbackArgComplex
+		case reflect.String:
+			p.kind = callbackArgString
+		case reflect.Pointer:
+			p.kind = callbackArgInt
+			if isStringPointerType(p.typ) {
+				p.kind = callbackArgNullString
+			}
+		case reflect.Slice:
+			p.kind = callbackArgSlice
+		default:
+			p.kind = callbackArgInt
+		}
+		f.decode(p.kind, &c, p.typ)
+		plan.params = append(plan.params, p)
+	}
+
+	if ty.NumOut() == 1 {
+		plan.setResult = callbackResultSetter(ty.Out(0))
+		plan.outType = ty.Out(0)
+		plan.out = plan.outType.Kind()
+	}
+	plan.directRegs, _ = directRegs(ty)
+	return plan
+}
+
+// decode reads an argument of type inType and the given kind at c and advances c past it.
+func (f *callbackFrames) decode(kind callbackArgKind, c *callbackCursor, inType reflect.Type) reflect.Value {
+	switch kind {
+	case callbackArgFloat:
+		return f.nextFloat(c, inType)
+	case callbackArgStruct:
+		return getCallbackStruct(inType, f.args, &c.floatsN, &c.intsN, &c.stackSlot, &c.stackByteOffset)
+	case callbackArgComplex:
+		v := getCallbackStruct(cLayoutType(inType), f.args, &c.floatsN, &c.intsN, &c.stackSlot, &c.stackByteOffset)
+		return fromCLayout(inType, v)
+	case callbackArgString:
+		// A string is received as a NUL-terminated char* and copied.
+		p := f.nextInt(c, reflect.TypeFor[uintptr]()).Uint()
+		return reflect.ValueOf(strings.GoString(uintptr(p))).Convert(inType)
+	case callbackArgNullString:
+		s := goNullString(uintptr(f.nextInt(c, reflect.TypeFor[uintptr]()).Uint()))
+		if inType.Kind() == reflect.Struct {
+			return reflect.ValueOf(s)
+		}
+		if !s.Valid {
+			return reflect.Zero(inType)
+		}
+		return reflect.ValueOf(&s.String).Convert(inType)
+	case callbackArgSlice:
+		// A slice is received as a pointer followed by a length.
+		// It refers to C memory and is only valid until the callback returns.
+		p := f.nextInt(c, reflect.TypeFor[unsafe.Pointer]()).UnsafePointer()
+		n := f.nextInt(c, reflect.TypeFor[int]()).Int()
+		return reflect.SliceAt(inType.Elem(), p, int(n)).Convert(inType)
+	case callbackArgZero:
+		return reflect.Zero(inType)
+	case callbackArgInt128:
+		return f.nextInt128(c, inType)
+	default:
+		return f.nextInt(c, inType)
+	}
+}
+
+// nextFloat reads an argument of type inType that is passed in a float register or on the stack.
+func (f *callbackFrames) nextFloat(c *callbackCursor, inType reflect.Type) reflect.Value {
+	var v reflect.Value
+	slots := int((inType.Size() + ptrSize - 1) / ptrSize)
+	if c.floatsN+slots > numOfFloatRegisters() {
+		if isDarwin && runtime.GOARCH == "arm64" {
+			// Darwin ARM64: read from packed stack with proper alignment
+			v = callbackArgFromStack(f.args, c.stackSlot, &c.stackByteOffset, inType)
+		} else if f.stackFrame != nil {
+			// ppc64le/s390x: stack args are in separate stackFrame
+			switch runtime.GOARCH {
+			case "ppc64le":
+				v = callbackFloatFromDoubleSlot(unsafe.Pointer(&f.stackFrame[c.stackSlot]), inType)
+			case "s390x":
+				// s390x big-endian: sub-8-byte values are right-justified
+				v = callbackArgFromSlotBigEndian(unsafe.Pointer(&f.stackFrame[c.stackSlot]), inType)
+			default:
+				v = reflect.NewAt(inType, unsafe.Pointer(&f.stackFrame[c.stackSlot])).Elem()
+			}
+			c.stackSlot += slots
+		} else {
+			v = reflect.NewAt(inType, unsafe.Pointer(&f.frame[c.stackSlot])).Elem()
+			c.stackSlot += slots
+		}
+	} else {
+		switch runtime.GOARCH {
+		case "ppc64le":
+			v = callbackFloatFromDoubleSlot(unsafe.Pointer(&f.frame[c.floatsN]), inType)
+		case "s390x":
+			// s390x big-endian: float32 is right-justified in 8-byte FPR slot
+			v = callbackArgFromSlotBigEndian(unsafe.Pointer(&f.frame[c.floatsN]), inType)
 		default:
-			slots = int((inType.Size() + ptrSize - 1) / ptrSize)
-			if intsN+slots > numOfIntegerRegisters() {
-				if isDarwin && runtime.GOARCH == "arm64" {
-					// Darwin ARM64: read from packed stack with proper alignment
-					args[i] = callbackArgFromStack(a.args, stackSlot, &stackByteOffset, inType)
-				} else if stackFrame != nil {
-					// ppc64le/s390x: stack args are in separate stackFrame
-					if runtime.GOARCH == "s390x" {
-						// s390x big-endian: sub-8-byte values are right-justified
-						args[i] = callbackArgFromSlotBigEndian(unsafe.Pointer(&stackFrame[stackSlot]), inType)
-					} else {
-						args[i] = reflect.NewAt(inType, unsafe.Pointer(&stackFrame[stackSlot])).Elem()
-					}
-					stackSlot += slots
-				} else {
-					args[i] = reflect.NewAt(inType, unsafe.Pointer(&frame[stackSlot])).Elem()
-					stackSlot += slots
-				}
+			v = reflect.NewAt(inType, unsafe.Pointer(&f.frame[c.floatsN])).Elem()
+		}
+	}
+	c.floatsN += slots
+	return v
+}
+
+// nextInt reads an argument of type inType that is passed in an integer register or on the stack.
+func (f *callbackFrames) nextInt(c *callbackCursor, inType reflect.Type) reflect.Value {
+	var v reflect.Value
+	slots := int((inType.Size() + ptrSize - 1) / ptrSize)
+	if c.intsN+slots > numOfIntegerRegisters() {
+		if isDarwin && runtime.GOARCH == "arm64" {
+			// Darwin ARM64: read from packed stack with proper alignment
+			v = callbackArgFromStack(f.args, c.stackSlot, &c.stackByteOffset, inType)
+		} else if f.stackFrame != nil {
+			// ppc64le/s390x: stack args are in separate stackFrame
+			if runtime.GOARCH == "s390x" {
+				// s390x big-endian: sub-8-byte values are right-justified
+				v = callbackArgFromSlotBigEndian(unsafe.Pointer(&f.stackFrame[c.stackSlot]), inType)
+			} else {
+				v = reflect.NewAt(inType, unsafe.Pointer(&f.stackFrame[c.stackSlot])).Elem()
+			}
+			c.stackSlot += slots
+		} else {
+			v = reflect.NewAt(inType, unsafe.Pointer(&f.frame[c.stackSlot])).Elem()
+			c.stackSlot += slots
+		}
+	} else {
+		if f.intFrame != nil {
+			v = reflect.NewAt(inType, unsafe.Pointer(&f.intFrame[c.intsN])).Elem()
+		} else {
+			// the integers begin after the floats in frame
+			pos := c.intsN + numOfFloatRegisters()
+			if runtime.GOARCH == "s390x" {
+				// s390x big-endian: sub-8-byte values are right-justified in GPR slot
+				v = callbackArgFromSlotBigEndian(unsafe.Pointer(&f.frame[pos]), inType)
 			} else {
-				if intFrame != nil {
-					args[i] = reflect.NewAt(inType, unsafe.Pointer(&intFrame[intsN])).Elem()
-				} else {
-					// the integers begin after the floats in frame
-					pos := intsN + numOfFloatRegisters()
-					if runtime.GOARCH == "s390x" {
-						// s390x big-endian: sub-8-byte values are right-justified in GPR slot
-						args[i] = callbackArgFromSlotBigEndian(unsafe.Pointer(&frame[pos]), inType)
-					} else {
-						args[i] = reflect.NewAt(inType, unsafe.Pointer(&frame[pos])).Elem()
-					}
-				}
+				v = reflect.NewAt(inType, unsafe.Pointer(&f.frame[pos])).Elem()
 			}
-			intsN += slots
 		}
 	}
-	ret := fn.Call(args)
-	if len(ret) > 0 {
-		switch k := ret[0].Kind(); k {
-		case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uintptr:
-			a.result[0] = uintptr(ret[0].Uint())
-		case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
-			a.result[0] = uintptr(ret[0].Int())
-		case reflect.Bool:
-			if ret[0].Bool() {
+	c.intsN += slots
+	return v
+}
+
+// nextInt128 reads an Int128 or a Uint128, which is passed in a pair of integer registers
+// or on the stack aligned to 16 bytes.
+func (f *callbackFrames) nextInt128(c *callbackCursor, inType reflect.Type) reflect.Value {
+	if runtime.GOARCH == "arm64" {
+		// The pair starts at an even-numbered register.
+		c.intsN += c.intsN % 2
+	}
+	if c.intsN+2 <= numOfIntegerRegisters() {
+		// the integers begin after the floats in frame
+		v := reflect.NewAt(inType, unsafe.Pointer(&f.frame[numOfFloatRegisters()+c.intsN])).Elem()
+		c.intsN += 2
+		return v
+	}
+	if runtime.GOARCH == "arm64" {
+		// No later argument uses the integer registers.
+		c.intsN = numOfIntegerRegisters()
+	}
+	if isDarwin && runtime.GOARCH == "arm64" {
+		c.stackByteOffset = alignUp(c.stackByteOffset, 16)
+		return callbackArgFromStack(f.args, c.stackSlot, &c.stackByteOffset, inType)
+	}
+	// The stack begins after the float and integer registers in frame at a 16-byte boundary.
+	if (c.stackSlot-numOfIntegerRegisters()-numOfFloatRegisters())%2 != 0 {
+		c.stackSlot++
+	}
+	v := reflect.NewAt(inType, unsafe.Pointer(&f.frame[c.stackSlot])).Elem()
+	c.stackSlot += 2
+	return v
+}
+
+// callbackResultSetter returns the function that stores a result of type outType in callbackArgs.
+func callbackResultSetter(outType reflect.Type) func(a *callbackArgs, v reflect.Value) {
+	switch k := outType.Kind(); k {
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uintptr:
+		return func(a *callbackArgs, v reflect.Value) {
+			a.result[0] = uintptr(v.Uint())
+		}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return func(a *callbackArgs, v reflect.Value) {
+			a.result[0] = uintptr(v.Int())
+		}
+	case reflect.Bool:
+		return func(a *callbackArgs, v reflect.Value) {
+			if v.Bool() {
 				a.result[0] = 1
 			} else {
 				a.result[0] = 0
 			}
-		case reflect.Pointer:
-			a.result[0] = ret[0].Pointer()
-		case reflect.UnsafePointer:
-			a.result[0] = ret[0].Pointer()
-		case reflect.Struct:
-			setStruct(a, ret[0])
-		default:
-			panic("purego: unsupported kind: " + k.String())
 		}
+	case reflect.Pointer, reflect.UnsafePointer:
+		return func(a *callbackArgs, v reflect.Value) {
+			a.result[0] = v.Pointer()
+		}
+	case reflect.Float32, reflect.Float64:
+		return setFloatResult
+	case reflect.Struct, reflect.Complex64, reflect.Complex128:
+		if cLayoutType(outType) != outType {
+			return func(a *callbackArgs, v reflect.Value) {
+				setStruct(a, toCLayout(v))
+			}
+		}
+		return setStruct
+	default:
+		panic("purego: unsupported kind: " + k.String())
+	}
+}
+
+// setFloatResult stores the floating-point result of a callback where the
+// assembly trampoline loads it into the C float return register.
+func setFloatResult(a *callbackArgs, v reflect.Value) {
+	setFloatBits(a, v.Float(), v.Kind() == reflect.Float32)
+}
+
+// setFloatBits stores f, which is a float32 if is32 is true, like setFloatResult.
+func setFloatBits(a *callbackArgs, f float64, is32 bool) {
+	// Index through a slice since result only has one element on ppc64le and s390x.
+	result := a.result[:]
+	bits := math.Float64bits(f)
+	if is32 {
+		bits = uint64(math.Float32bits(float32(f)))
+	}
+	switch runtime.GOARCH {
+	case "amd64":
+		// XMM0 is loaded from result[2].
+		result[2] = uintptr(bits)
+	case "arm64", "loong64":
+		result[0] = uintptr(bits)
+	case "riscv64":
+		if is32 {
+			// A float32 in a 64-bit register must be NaN-boxed.
+			bits |= 0xFFFFFFFF << 32
+		}
+		result[0] = uintptr(bits)
+	case "ppc64le":
+		// F1 holds a float32 in double-precision format.
+		result[0] = uintptr(math.Float64bits(f))
+	case "s390x":
+		if is32 {
+			// A float32 is left-justified in F0.
+			bits <<= 32
+		}
+		result[0] = uintptr(bits)
+	case "arm":
+		// D0 is loaded from result[0] and result[1], and S0 is its low half.
+		result[0] = uintptr(bits)
+		result[1] = uintptr(bits >> 32)
+	case "386":
+		// ST(0) is loaded from result[0] and result[1] with the size given by result[2].
+		result[0] = uintptr(bits)
+		result[1] = uintptr(bits >> 32)
+		result[2] = 8
+		if is32 {
+			result[2] = 4
+		}
+	default:
+		panic("purego: unsupported architecture")
+	}
+}
+
+var callbackPanicHandler atomic.Pointer[func(v any) uintptr]
+
+// SetCallbackPanicHandler sets the function that handles a panic in the Go function of a callback
+// created by [NewCallback] or [NewClosure]. Without a handler, the panic unwinds through the C frames
+// that called the callback, which crashes the process unless the C function was called from Go.
+//
+// When a handler is set, the panic is recovered before it reaches C and h is called with the panic value.
+// The callback then returns the result of h to C as an integer, bool or pointer. Callbacks returning
+// a floating-point number, a complex number or a struct return zero instead.
+//
+// Unlike a panic, runtime.Goexit in the Go function of a callback, for example through
+// testing.T.FailNow, is not turned into a return value: h is not called, and the process crashes with
+// a fatal error whether or not a handler is set. A Goexit cannot be stopped once it starts, even by
+// recovering a later panic, and the goroutine cannot exit through the C frames that called the callback.
+//
+// Passing nil removes the handler.
+func SetCallbackPanicHandler(h func(v any) uintptr) {
+	if h == nil {
+		callbackPanicHandler.Store(nil)
+		return
 	}
+	callbackPanicHandler.Store(&h)
 }
 
 // callbackArgFromStack reads an argument from the tightly-packed stack area on Darwin ARM64.
@@ -337,23 +922,26 @@ func callbackArgFromSlotBigEndian(slotPtr unsafe.Pointer, inType reflect.Type) r
 // R12 is loaded with the callback index. Each entry is two instructions,
 // hence 8 bytes.
 func callbackasmAddr(i int) uintptr {
-	var entrySize int
+	return callbackasmABI0 + uintptr(i*callbackasmEntrySize())
+}
+
+// callbackasmEntrySize returns the size in bytes of each entry of runtime.callbackasm.
+func callbackasmEntrySize() int {
 	switch runtime.GOARCH {
 	default:
 		panic("purego: unsupported architecture")
 	case "amd64":
 		// On amd64, each callback entry is just a CALL instruction (5 bytes)
-		entrySize = 5
+		return 5
 	case "386":
 		// On 386, each callback entry is MOVL $imm, CX (5 bytes) + JMP (5 bytes)
-		entrySize = 10
+		return 10
 	case "arm", "arm64", "loong64", "ppc64le", "riscv64":
 		// On ARM, ARM64, Loong64, PPC64LE and RISCV64, each entry is a MOV instruction
 		// followed by a branch instruction
-		entrySize = 8
+		return 8
 	case "s390x":
 		// On S390X, each entry is LGHI (4 bytes) + JG (6 bytes)
-		entrySize = 10
+		return 10
 	}
-	return callbackasmABI0 + uintptr(i*entrySize)
 }
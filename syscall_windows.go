@@ -42,6 +42,26 @@ func NewCallback(fn any) uintptr {
 	return syscall.NewCallback(fn)
 }
 
+// ReleaseCallback is not supported on Windows since callbacks created by
+// syscall.NewCallback are never released. It always panics.
+func ReleaseCallback(ptr uintptr) {
+	panic("purego: ReleaseCallback is not supported on Windows")
+}
+
+// SetCallbackPanicHandler is not supported on Windows since callbacks are run by
+// syscall.NewCallback. It always panics.
+func SetCallbackPanicHandler(h func(v any) uintptr) {
+	panic("purego: SetCallbackPanicHandler is not supported on Windows")
+}
+
+func compileClosure(_ any, _ any) (ptr, index uintptr) {
+	panic("purego: NewClosure is not supported on Windows")
+}
+
+func releaseClosure(_ uintptr) {
+	panic("purego: NewClosure is not supported on Windows")
+}
+
 func loadSymbol(handle uintptr, name string) (uintptr, error) {
 	return syscall.GetProcAddress(syscall.Handle(handle), name)
 }
@@ -3,6 +3,7 @@
 
 #include <assert.h>
 #include <inttypes.h>
+#include <stdarg.h>
 #include <stdbool.h>
 #include <stdint.h>
 #include <stdio.h>
@@ -32,6 +33,17 @@ void stack_8i32_3strings(char* result, size_t size, int32_t a1, int32_t a2, int3
     snprintf(result, size, "%d:%d:%d:%d:%d:%d:%d:%d:%s:%s:%s", a1, a2, a3, a4, a5, a6, a7, a8, s1, s2, s3);
 }
 
+// variadic_fixed_floats formats its fixed float and double and the n doubles that follow them.
+void variadic_fixed_floats(char *result, size_t size, float f, double d, int n, ...) {
+    int len = snprintf(result, size, "%.1f:%.1f", f, d);
+    va_list ap;
+    va_start(ap, n);
+    for (int i = 0; i < n; i++) {
+        len += snprintf(result + len, size - len, ":%.1f", va_arg(ap, double));
+    }
+    va_end(ap);
+}
+
 // HFA (Homogeneous Float Aggregate) struct with 2 floats
 typedef struct {
     float x;
@@ -167,3 +179,68 @@ double stack_32_mixed_int_float(
            f9 * 25 + f10 * 26 + f11 * 27 + f12 * 28 +
            f13 * 29 + f14 * 30 + f15 * 31 + f16 * 32;
 }
+
+#ifdef __SIZEOF_INT128__
+__int128 int128_add(__int128 a, __int128 b) {
+    return a + b;
+}
+
+unsigned __int128 uint128_mul(unsigned __int128 a, unsigned __int128 b) {
+    return a * b;
+}
+
+// int128_after_odd skips x1 on arm64 since a pair starts at an even-numbered register.
+__int128 int128_after_odd(int64_t a, __int128 b) {
+    return b - a;
+}
+
+// int128_one_reg_left passes x on the stack on amd64 where only one register is left,
+// and b in that register.
+__int128 int128_one_reg_left(int64_t a1, int64_t a2, int64_t a3, int64_t a4, int64_t a5, __int128 x, int64_t b) {
+    return x + b - (a1 + a2 + a3 + a4 + a5);
+}
+
+// int128_stack_after_odd passes x on the stack after an odd number of stack words,
+// which leaves a hole to align it to 16 bytes.
+__int128 int128_stack_after_odd(int64_t a1, int64_t a2, int64_t a3, int64_t a4, int64_t a5, int64_t a6, int64_t a7, int64_t a8,
+                                int64_t s, __int128 x) {
+    return x + s - (a1 + a2 + a3 + a4 + a5 + a6 + a7 + a8);
+}
+
+// int128_after_odd_stack is int128_after_odd with the pair on the stack on arm64,
+// where x7 is left unused.
+__int128 int128_after_odd_stack(int64_t a1, int64_t a2, int64_t a3, int64_t a4, int64_t a5, int64_t a6, int64_t a7, __int128 x, int64_t b) {
+    return x + b - (a1 + a2 + a3 + a4 + a5 + a6 + a7);
+}
+
+typedef struct {
+    __int128 value;
+} Box128;
+
+// box128_after_odd aligns a struct of one __int128 like the __int128 itself.
+__int128 box128_after_odd(int32_t a, Box128 b) {
+    return b.value - a;
+}
+
+typedef struct {
+    int32_t tag;
+    __int128 value;
+} Tagged128;
+
+__int128 tagged128_value(int64_t a1, int64_t a2, int64_t a3, int64_t a4, int64_t a5, int64_t a6, int64_t a7, Tagged128 t) {
+    return t.value + t.tag - (a1 + a2 + a3 + a4 + a5 + a6 + a7);
+}
+
+Tagged128 make_tagged128(int32_t tag, __int128 value) {
+    Tagged128 t = {tag, value};
+    return t;
+}
+
+__int128 int128_callback(__int128 (*cb)(int64_t, __int128), int64_t a, __int128 b) {
+    return cb(a, b);
+}
+
+__int128 int128_callback_stack(__int128 (*cb)(int64_t, int64_t, int64_t, int64_t, int64_t, int64_t, int64_t, __int128), __int128 x) {
+    return cb(1, 2, 3, 4, 5, 6, 7, x);
+}
+#endif
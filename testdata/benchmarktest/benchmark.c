@@ -79,3 +79,8 @@ int64_t call_callback15(callback15_t cb, int64_t a1, int64_t a2, int64_t a3,
                         int64_t a12, int64_t a13, int64_t a14, int64_t a15) {
   return cb(a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15);
 }
+
+typedef int32_t (*compare_t)(const void *, const void *);
+int32_t call_compare(compare_t cmp, const void *a, const void *b) {
+  return cmp(a, b);
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <complex.h>
+#include <stdint.h>
+
+double complex multiply128(double complex a, double complex b) {
+    return a * b;
+}
+
+float complex multiply64(float complex a, float complex b) {
+    return a * b;
+}
+
+double norm128(double complex a) {
+    return creal(a) * creal(a) + cimag(a) * cimag(a);
+}
+
+// mixed takes complex numbers between integer and floating-point arguments.
+double complex mixed(int32_t a, float complex b, double c, double complex d, int64_t e) {
+    return a + b + c + d + e;
+}
+
+// sum5 takes more complex numbers than there are float registers so that the last ones are on the stack.
+double complex sum5(double complex a, double complex b, double complex c, double complex d, double complex e) {
+    return a + b + c + d + e;
+}
+
+struct Signal {
+    int32_t id;
+    float complex gain;
+    double complex phase;
+};
+
+double complex signalSum(struct Signal s) {
+    return s.id + s.gain + s.phase;
+}
+
+struct Sample {
+    float complex value;
+    float weight;
+};
+
+struct Sample makeSample(float complex value, float weight) {
+    struct Sample s = {value, weight};
+    return s;
+}
+
+double complex callComplex(double complex (*cb)(double complex, float complex), double complex a, float complex b) {
+    return cb(a, b);
+}
+
+struct Signal callSignal(struct Signal (*cb)(struct Signal), struct Signal s) {
+    return cb(s);
+}
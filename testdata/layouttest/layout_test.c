@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <complex.h>
+#include <stddef.h>
+
+struct Mixed {
+    char a;
+    int b;
+    long long c;
+    short d;
+    double e;
+    char f;
+};
+
+#define FIELD(s, f, out, i) \
+    out[i] = offsetof(struct s, f); \
+    out[i + 1] = sizeof(((struct s *)0)->f);
+
+// MixedLayout stores the offset and size of each field of struct Mixed
+// followed by the size of the struct.
+void MixedLayout(size_t *out) {
+    FIELD(Mixed, a, out, 0)
+    FIELD(Mixed, b, out, 2)
+    FIELD(Mixed, c, out, 4)
+    FIELD(Mixed, d, out, 6)
+    FIELD(Mixed, e, out, 8)
+    FIELD(Mixed, f, out, 10)
+    out[12] = sizeof(struct Mixed);
+}
+
+struct Nested {
+    char a;
+    struct Mixed m;
+    short s[5];
+    float complex z;
+    char e;
+};
+
+// NestedLayout stores the offset and size of each field of struct Nested
+// followed by the size of the struct.
+void NestedLayout(size_t *out) {
+    FIELD(Nested, a, out, 0)
+    FIELD(Nested, m, out, 2)
+    FIELD(Nested, s, out, 4)
+    FIELD(Nested, z, out, 6)
+    FIELD(Nested, e, out, 8)
+    out[10] = sizeof(struct Nested);
+}
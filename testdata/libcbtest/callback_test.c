@@ -1,6 +1,8 @@
 // SPDX-License-Identifier: Apache-2.0
 // SPDX-FileCopyrightText: 2023 The Ebitengine Authors
 
+#include <pthread.h>
+#include <stddef.h>
 #include <string.h>
 
 typedef int (*callback)(const char *, int);
@@ -11,3 +13,141 @@ int callCallback(const void *fp, const char *s) {
     ((callback)(fp))(s, strlen(s));
     return sentinel;
 }
+
+typedef double (*double_callback)(double, int);
+
+double callDoubleCallback(const void *fp, double x, int n) {
+    return ((double_callback)(fp))(x, n) + 0.5;
+}
+
+typedef float (*float_callback)(float, int);
+
+float callFloatCallback(const void *fp, float x, int n) {
+    return ((float_callback)(fp))(x, n) + 0.5f;
+}
+
+typedef void (*log_callback)(int, const char *);
+
+void callLogCallback(const void *fp, int level, const char *msg) {
+    ((log_callback)(fp))(level, msg);
+}
+
+typedef int (*sum_callback)(const int *, size_t);
+
+int callSumCallback(const void *fp) {
+    int values[] = {1, 2, 3, 4, 5};
+    return ((sum_callback)(fp))(values, sizeof(values) / sizeof(values[0]));
+}
+
+typedef int (*thread_callback)(int);
+
+struct thread_arg {
+    thread_callback fn;
+    int n;
+    int result;
+};
+
+static void *threadEntry(void *p) {
+    struct thread_arg *arg = p;
+    for (int i = 0; i < arg->n; i++) {
+        arg->result += arg->fn(i);
+    }
+    return NULL;
+}
+
+// callCallbackFromThreads calls fp from numThreads new threads calls times each
+// and returns the sum of the results.
+int callCallbackFromThreads(const void *fp, int numThreads, int calls) {
+    pthread_t threads[16];
+    struct thread_arg args[16];
+    if (numThreads > 16) {
+        return -1;
+    }
+    for (int i = 0; i < numThreads; i++) {
+        args[i].fn = (thread_callback)fp;
+        args[i].n = calls;
+        args[i].result = 0;
+        if (pthread_create(&threads[i], NULL, threadEntry, &args[i]) != 0) {
+            return -1;
+        }
+    }
+    int sum = 0;
+    for (int i = 0; i < numThreads; i++) {
+        pthread_join(threads[i], NULL);
+        sum += args[i].result;
+    }
+    return sum;
+}
+
+// A stepped thread calls its callback once for every stepThread and exits on stopThread,
+// so that the caller can look at the Go runtime between the calls of one thread.
+static pthread_mutex_t stepMutex = PTHREAD_MUTEX_INITIALIZER;
+static pthread_cond_t stepCond = PTHREAD_COND_INITIALIZER;
+static pthread_t steppedThread;
+static thread_callback stepFn;
+static int stepArg, stepResult, stepPending, stepStop;
+
+static void *steppedThreadEntry(void *p) {
+    (void)p;
+    pthread_mutex_lock(&stepMutex);
+    for (;;) {
+        while (!stepPending && !stepStop) {
+            pthread_cond_wait(&stepCond, &stepMutex);
+        }
+        if (stepStop) {
+            break;
+        }
+        int n = stepArg;
+        pthread_mutex_unlock(&stepMutex);
+        int result = stepFn(n);
+        pthread_mutex_lock(&stepMutex);
+        stepResult = result;
+        stepPending = 0;
+        pthread_cond_broadcast(&stepCond);
+    }
+    pthread_mutex_unlock(&stepMutex);
+    return NULL;
+}
+
+// startThread starts a thread that calls fp once for every stepThread.
+int startThread(const void *fp) {
+    stepFn = (thread_callback)fp;
+    stepPending = 0;
+    stepStop = 0;
+    return pthread_create(&steppedThread, NULL, steppedThreadEntry, NULL);
+}
+
+// stepThread makes the thread started by startThread call its callback with n
+// and returns the result.
+int stepThread(int n) {
+    pthread_mutex_lock(&stepMutex);
+    stepArg = n;
+    stepPending = 1;
+    pthread_cond_broadcast(&stepCond);
+    while (stepPending) {
+        pthread_cond_wait(&stepCond, &stepMutex);
+    }
+    int result = stepResult;
+    pthread_mutex_unlock(&stepMutex);
+    return result;
+}
+
+// stopThread makes the thread started by startThread exit and waits for it.
+void stopThread(void) {
+    pthread_mutex_lock(&stepMutex);
+    stepStop = 1;
+    pthread_cond_broadcast(&stepCond);
+    pthread_mutex_unlock(&stepMutex);
+    pthread_join(steppedThread, NULL);
+}
+
+static thread_callback registeredHandler;
+
+// registerHandler keeps fp to be called later by callRegisteredHandler.
+void registerHandler(const void *fp) {
+    registeredHandler = (thread_callback)fp;
+}
+
+int callRegisteredHandler(int n) {
+    return registeredHandler(n);
+}
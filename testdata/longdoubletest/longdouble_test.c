@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <stdint.h>
+
+long double addLongDouble(long double a, long double b) {
+    return a + b;
+}
+
+long double third(void) {
+    return 1.0L / 3.0L;
+}
+
+int isThird(long double x) {
+    return x == 1.0L / 3.0L;
+}
+
+// mixed takes long doubles between integer and floating-point arguments.
+long double mixed(int32_t a, long double b, double c, long double d, int64_t e) {
+    return a + b + c + d + e;
+}
+
+// afterInts passes x on the stack after an odd number of stack arguments on amd64.
+long double afterInts(int64_t a, int64_t b, int64_t c, int64_t d, int64_t e, int64_t f, int64_t g, long double x) {
+    return a + b + c + d + e + f + g + x;
+}
+
+// sum9 passes the last long double on the stack on arm64.
+long double sum9(long double a, long double b, long double c, long double d, long double e,
+                 long double f, long double g, long double h, long double i) {
+    return a + b + c + d + e + f + g + h + i;
+}
+
+struct box {
+    long double value;
+};
+
+struct box makeBox(double value) {
+    struct box b = {value};
+    return b;
+}
+
+struct pair {
+    long double a, b;
+};
+
+long double pairSum(struct pair p) {
+    return p.a + p.b;
+}
+
+struct pair makePair(long double a, long double b) {
+    struct pair p = {a, b};
+    return p;
+}
+
+struct tagged {
+    int32_t tag;
+    long double value;
+};
+
+long double taggedValue(struct tagged t) {
+    return t.tag + t.value;
+}
+
+struct tagged makeTagged(int32_t tag, long double value) {
+    struct tagged t = {tag, value};
+    return t;
+}
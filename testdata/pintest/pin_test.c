@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <stdint.h>
+
+// kept is a pointer that is used after the call that passed it returns.
+static int64_t *kept;
+
+void keepPointer(int64_t *p) {
+    kept = p;
+}
+
+// keepPointerStack is like keepPointer but p is passed on the stack.
+void keepPointerStack(intptr_t a, intptr_t b, intptr_t c, intptr_t d, intptr_t e, intptr_t f, intptr_t g, intptr_t h, int64_t *p) {
+    kept = p;
+}
+
+int64_t readKept(void) {
+    return *kept;
+}
+
+void writeKept(int64_t v) {
+    *kept = v;
+}
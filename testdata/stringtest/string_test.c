@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <stddef.h>
+#include <string.h>
+
+// joinStrings writes the strings of the NULL-terminated array arr separated by commas to out
+// and returns their number, or -1 if arr is NULL.
+int joinStrings(char **arr, char *out, size_t size) {
+    if (arr == NULL) {
+        return -1;
+    }
+    out[0] = '\0';
+    int n = 0;
+    for (; arr[n] != NULL; n++) {
+        if (n > 0) {
+            strncat(out, ",", size - strlen(out) - 1);
+        }
+        strncat(out, arr[n], size - strlen(out) - 1);
+    }
+    return n;
+}
+
+#include <stdlib.h>
+
+// splitString returns the words of s separated by commas as a NULL-terminated array
+// and sets count to their number. It returns NULL if s is NULL.
+char **splitString(const char *s, int *count) {
+    if (s == NULL) {
+        *count = 0;
+        return NULL;
+    }
+    int n = 1;
+    for (const char *p = s; *p != '\0'; p++) {
+        if (*p == ',') {
+            n++;
+        }
+    }
+    char **arr = malloc((n + 1) * sizeof(char *));
+    for (int i = 0; i < n; i++) {
+        const char *end = strchr(s, ',');
+        size_t len = end != NULL ? (size_t)(end - s) : strlen(s);
+        arr[i] = malloc(len + 1);
+        memcpy(arr[i], s, len);
+        arr[i][len] = '\0';
+        s += len + 1;
+    }
+    arr[n] = NULL;
+    *count = n;
+    return arr;
+}
+
+static int freed;
+
+// freeStrings frees an array returned by splitString.
+void freeStrings(char **arr) {
+    for (int i = 0; arr[i] != NULL; i++) {
+        free(arr[i]);
+    }
+    free(arr);
+    freed++;
+}
+
+int freedStrings(void) {
+    return freed;
+}
+
+static const char *colors[] = {"red", "green", "blue"};
+
+// firstColors returns an array that is not terminated by NULL. Only the first n strings are valid.
+const char **firstColors(int n) {
+    return colors;
+}
+
+// stringLength returns the length of s, or -1 if s is NULL.
+int stringLength(const char *s) {
+    return s != NULL ? (int)strlen(s) : -1;
+}
+
+static char copied[64];
+
+// copyString returns a copy of s in static memory, or NULL if s is NULL.
+const char *copyString(const char *s) {
+    if (s == NULL) {
+        return NULL;
+    }
+    strncpy(copied, s, sizeof(copied) - 1);
+    return copied;
+}
+
+// callWithString calls cb with s.
+int callWithString(int (*cb)(const char *), const char *s) {
+    return cb(s);
+}
+
+// dupString returns a copy of s that must be freed with freeString, or NULL if s is NULL.
+char *dupString(const char *s) {
+    if (s == NULL) {
+        return NULL;
+    }
+    char *d = malloc(strlen(s) + 1);
+    strcpy(d, s);
+    return d;
+}
+
+static int freedDups;
+
+void freeString(void *p) {
+    free(p);
+    freedDups++;
+}
+
+int freedStringCount(void) {
+    return freedDups;
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <stdint.h>
+
+// Number is passed in an integer register since one of its members is an integer.
+union Number {
+    int64_t i;
+    double d;
+};
+
+double numberAsDouble(union Number n) {
+    return n.d;
+}
+
+union Number makeNumber(int64_t i) {
+    union Number n;
+    n.i = i;
+    return n;
+}
+
+// Pair is passed in float registers since all of its members are floats.
+union Pair {
+    float f[2];
+    float g;
+};
+
+float pairSum(union Pair p) {
+    return p.f[0] + p.f[1];
+}
+
+union Pair swapPair(union Pair p) {
+    union Pair q = {{p.f[1], p.f[0]}};
+    return q;
+}
+
+// Floats has float and double members, which are not a homogeneous aggregate on arm64.
+union Floats {
+    float f[2];
+    double d;
+};
+
+double floatsAsDouble(double a, union Floats f, double b) {
+    return a + f.d + b;
+}
+
+struct Tagged {
+    int32_t tag;
+    union Number value;
+};
+
+struct Tagged makeTagged(int32_t tag, int64_t i) {
+    struct Tagged t = {tag, {.i = i}};
+    return t;
+}
+
+int64_t taggedValue(struct Tagged t) {
+    return t.tag + t.value.i;
+}
+
+struct Point {
+    union Pair xy;
+    double z;
+};
+
+double pointSum(struct Point p) {
+    return p.xy.f[0] + p.xy.f[1] + p.z;
+}
+
+// manyPairs passes more unions than there are float registers.
+float manyPairs(union Pair a, union Pair b, union Pair c, union Pair d, union Pair e) {
+    return a.f[0] + b.f[0] + c.f[0] + d.f[0] + e.f[0] + a.f[1] + b.f[1] + c.f[1] + d.f[1] + e.f[1];
+}
+
+union Pair callPair(union Pair (*cb)(union Pair), union Pair p) {
+    return cb(p);
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+#include <stdint.h>
+
+// These are passed like __m128, __m128d and __m128i on amd64
+// and like float32x4_t, float64x2_t and int32x4_t on arm64.
+typedef float float4 __attribute__((vector_size(16)));
+typedef double double2 __attribute__((vector_size(16)));
+typedef int32_t int4 __attribute__((vector_size(16)));
+
+float4 addFloat4(float4 a, float4 b) {
+    return a + b;
+}
+
+// scaleDouble2 takes a vector between floating-point arguments.
+double2 scaleDouble2(double s, double2 v, double t) {
+    return v * s + t;
+}
+
+int4 addInt4(int4 a, int4 b) {
+    return a + b;
+}
+
+float sumFloat4(float4 v) {
+    return v[0] + v[1] + v[2] + v[3];
+}
+
+// sum9 passes the last vector on the stack.
+float sum9(float4 a, float4 b, float4 c, float4 d, float4 e, float4 f, float4 g, float4 h, float4 i) {
+    return sumFloat4(a + b + c + d + e + f + g + h + i);
+}
+
+// afterOddStack passes x on the stack after an 8-byte stack argument,
+// which leaves a hole to align it to 16 bytes.
+float afterOddStack(float4 a, float4 b, float4 c, float4 d, float4 e, float4 f, float4 g, float4 h, double s, float4 x) {
+    return sumFloat4(a + b + c + d + e + f + g + h) + s + sumFloat4(x);
+}
+
+struct box {
+    float4 value;
+};
+
+float boxSum(int32_t i, struct box b) {
+    return i + sumFloat4(b.value);
+}
+
+struct box makeBox(float x, float y, float z, float w) {
+    struct box b = {{x, y, z, w}};
+    return b;
+}
+
+struct pair {
+    float4 a, b;
+};
+
+float pairSum(struct pair p) {
+    return sumFloat4(p.a + p.b);
+}
+
+struct pair makePair(float4 a, float4 b) {
+    struct pair p = {a, b};
+    return p;
+}
+
+struct tagged {
+    int32_t tag;
+    float4 value;
+};
+
+float taggedSum(struct tagged t) {
+    return t.tag + sumFloat4(t.value);
+}
+
+struct tagged makeTagged(int32_t tag, float4 value) {
+    struct tagged t = {tag, value};
+    return t;
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// Union is a C union of the members T1 and T2 that can be passed and returned by value, or used as
+// a field of a struct that is. T1 must be at least as large as T2. A union with more members nests
+// another Union, for example Union[float64, Union[int32, [2]float32]] for
+//
+//	union { double d; int32_t i; float f[2]; }
+//
+// The calling conventions place a union according to all of its members, so a union cannot be
+// replaced by its largest member. Unions are only supported on amd64 and arm64.
+type Union[T1, T2 any] struct {
+	_     [0]T2 // aligns the union to both members
+	value T1
+}
+
+// First returns a pointer to the union as a T1.
+func (u *Union[T1, T2]) First() *T1 {
+	return &u.value
+}
+
+// Second returns a pointer to the union as a T2.
+func (u *Union[T1, T2]) Second() *T2 {
+	return (*T2)(unsafe.Pointer(&u.value))
+}
+
+func (Union[T1, T2]) union() {}
+
+// unionValue is implemented by every Union type and by the structs that embed one.
+type unionValue interface {
+	union()
+}
+
+// isUnionType reports whether t is a Union type.
+func isUnionType(t reflect.Type) bool {
+	return hasMarker(t, reflect.TypeFor[unionValue]())
+}
+
+// unionMembers returns the member types of the Union type t.
+func unionMembers(t reflect.Type) (t1, t2 reflect.Type) {
+	return t.Field(1).Type, t.Field(0).Type.Elem()
+}
+
+// containsUnion reports whether t is or contains a Union.
+func containsUnion(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		if isUnionType(t) {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if containsUnion(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return containsUnion(t.Elem())
+	}
+	return false
+}
+
+// homogeneousFloats reports whether every scalar in t, including every member of its unions, is
+// a floating-point number of the same kind, and returns that kind and how many of them t holds.
+// A complex number counts as two floating-point numbers.
+func homogeneousFloats(t reflect.Type) (kind reflect.Kind, n int, ok bool) {
+	var walk func(t reflect.Type) bool
+	walk = func(t reflect.Type) bool {
+		switch k := t.Kind(); k {
+		case reflect.Struct:
+			if isUnionType(t) {
+				t1, t2 := unionMembers(t)
+				return walk(t1) && walk(t2)
+			}
+			for i := 0; i < t.NumField(); i++ {
+				if !walk(t.Field(i).Type) {
+					return false
+				}
+			}
+			return true
+		case reflect.Array:
+			return walk(t.Elem())
+		case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			switch k {
+			case reflect.Complex64:
+				k = reflect.Float32
+			case reflect.Complex128:
+				k = reflect.Float64
+			}
+			if kind == reflect.Invalid {
+				kind = k
+			}
+			return kind == k
+		default:
+			return false
+		}
+	}
+	if !walk(t) || kind == reflect.Invalid {
+		return reflect.Invalid, 0, false
+	}
+	size := uintptr(4)
+	if kind == reflect.Float64 {
+		size = 8
+	}
+	return kind, int(t.Size() / size), true
+}
+
+// checkUnionSupported panics if unions are not supported on the current platform
+// or if the first member of the Union type t is smaller than the second.
+func checkUnionSupported(t reflect.Type) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		panic("purego: unions are only supported on amd64 and arm64")
+	}
+	if t1, t2 := unionMembers(t); t1.Size() < t2.Size() {
+		panic("purego: the first member of " + t.String() + " must be at least as large as the second")
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || linux || windows) && (amd64 || arm64)
+
+package purego_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+type (
+	number = purego.Union[int64, float64]
+	pair   = purego.Union[[2]float32, float32]
+	floats = purego.Union[[2]float32, float64]
+)
+
+func openUnionTest(t *testing.T) uintptr {
+	t.Helper()
+	libFileName := filepath.Join(t.TempDir(), "uniontest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "uniontest", "union_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+func newPair(x, y float32) pair {
+	var p pair
+	*p.First() = [2]float32{x, y}
+	return p
+}
+
+func TestUnion(t *testing.T) {
+	lib := openUnionTest(t)
+
+	var numberAsDouble func(n number) float64
+	purego.RegisterLibFunc(&numberAsDouble, lib, "numberAsDouble")
+	var n number
+	*n.Second() = 1.5
+	if got := numberAsDouble(n); got != 1.5 {
+		t.Errorf("numberAsDouble() = %v, want 1.5", got)
+	}
+
+	var makeNumber func(i int64) number
+	purego.RegisterLibFunc(&makeNumber, lib, "makeNumber")
+	n = makeNumber(int64(math.Float64bits(2.5)))
+	if got := *n.Second(); got != 2.5 {
+		t.Errorf("makeNumber() = %v, want 2.5", got)
+	}
+
+	var pairSum func(p pair) float32
+	purego.RegisterLibFunc(&pairSum, lib, "pairSum")
+	if got := pairSum(newPair(1, 2)); got != 3 {
+		t.Errorf("pairSum() = %v, want 3", got)
+	}
+
+	var swapPair func(p pair) pair
+	purego.RegisterLibFunc(&swapPair, lib, "swapPair")
+	swapped := swapPair(newPair(1, 2))
+	if got := *swapped.First(); got != [2]float32{2, 1} {
+		t.Errorf("swapPair() = %v, want [2 1]", got)
+	}
+
+	var floatsAsDouble func(a float64, f floats, b float64) float64
+	purego.RegisterLibFunc(&floatsAsDouble, lib, "floatsAsDouble")
+	var f floats
+	*f.Second() = 2
+	if got := floatsAsDouble(1, f, 4); got != 7 {
+		t.Errorf("floatsAsDouble() = %v, want 7", got)
+	}
+
+	type tagged struct {
+		Tag   int32
+		Value number
+	}
+	var makeTagged func(tag int32, i int64) tagged
+	purego.RegisterLibFunc(&makeTagged, lib, "makeTagged")
+	tg := makeTagged(3, 40)
+	if tg.Tag != 3 || *tg.Value.First() != 40 {
+		t.Errorf("makeTagged() = {%d, %d}, want {3, 40}", tg.Tag, *tg.Value.First())
+	}
+	var taggedValue func(t tagged) int64
+	purego.RegisterLibFunc(&taggedValue, lib, "taggedValue")
+	if got := taggedValue(tg); got != 43 {
+		t.Errorf("taggedValue() = %d, want 43", got)
+	}
+
+	type point struct {
+		XY pair
+		Z  float64
+	}
+	var pointSum func(p point) float64
+	purego.RegisterLibFunc(&pointSum, lib, "pointSum")
+	if got := pointSum(point{XY: newPair(1, 2), Z: 3}); got != 6 {
+		t.Errorf("pointSum() = %v, want 6", got)
+	}
+
+	var manyPairs func(a, b, c, d, e pair) float32
+	purego.RegisterLibFunc(&manyPairs, lib, "manyPairs")
+	if got := manyPairs(newPair(1, 2), newPair(3, 4), newPair(5, 6), newPair(7, 8), newPair(9, 10)); got != 55 {
+		t.Errorf("manyPairs() = %v, want 55", got)
+	}
+}
+
+func TestUnion_Unsupported(t *testing.T) {
+	var fn func(u purego.Union[int32, int64])
+	if err := purego.TryRegisterFunc(&fn, 1); err == nil {
+		t.Error("TryRegisterFunc returned no error for a Union whose first member is smaller")
+	}
+}
+
+func TestUnionCallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unions in callbacks are not supported on Windows")
+	}
+	lib := openUnionTest(t)
+
+	var callPair func(cb func(p pair) pair, p pair) pair
+	purego.RegisterLibFunc(&callPair, lib, "callPair")
+	got := callPair(func(p pair) pair {
+		xy := p.First()
+		return newPair(xy[0]*10, xy[1]*10)
+	}, newPair(1, 2))
+	if xy := *got.First(); xy != [2]float32{10, 20} {
+		t.Errorf("callPair() = %v, want [10 20]", xy)
+	}
+}
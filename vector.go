@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package purego
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// VectorElem is the type of the elements of a [Vec128].
+type VectorElem interface {
+	int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64
+}
+
+// Vec128 is a 128-bit SIMD vector of elements of type T, such as __m128 (Vec128[float32]), __m128d
+// (Vec128[float64]) and __m128i on amd64, or float32x4_t (Vec128[float32]) and int32x4_t
+// (Vec128[int32]) on arm64. It is passed and returned whole in a vector register, either directly or
+// as a field of a struct, instead of element by element like a [4]float32.
+//
+// Vec128 is supported on amd64 and arm64 except on windows/amd64, which passes vectors by reference.
+// C aligns a vector to 16 bytes while Go aligns a Vec128 to 8 bytes, so a struct field may need
+// explicit padding, which [CheckLayout] reports. Vec128 is not supported in callbacks, unions or
+// ...any arguments.
+type Vec128[T VectorElem] struct {
+	words [2]uint64
+}
+
+// NewVec128 returns a Vec128 of elems followed by zeros.
+// It panics if there are more elements than fit in 16 bytes.
+func NewVec128[T VectorElem](elems ...T) Vec128[T] {
+	var v Vec128[T]
+	if copy(v.Elems(), elems) < len(elems) {
+		panic("purego: too many elements for a Vec128")
+	}
+	return v
+}
+
+// Elems returns the elements of v, which share its memory.
+func (v *Vec128[T]) Elems() []T {
+	var zero T
+	return unsafe.Slice((*T)(unsafe.Pointer(&v.words)), unsafe.Sizeof(v.words)/unsafe.Sizeof(zero))
+}
+
+func (Vec128[T]) vector() {}
+
+// vectorValue is implemented by every Vec128 type and by the structs that embed one.
+type vectorValue interface {
+	vector()
+}
+
+// isVectorType reports whether t is a Vec128 type.
+func isVectorType(t reflect.Type) bool {
+	return hasMarker(t, reflect.TypeFor[vectorValue]())
+}
+
+// containsVector reports whether t is or contains a Vec128.
+func containsVector(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		if isVectorType(t) {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if containsVector(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return containsVector(t.Elem())
+	}
+	return false
+}
+
+// homogeneousVectors reports whether t is a Vec128 or an aggregate of only Vec128 of any element type,
+// and returns how many of them t holds.
+func homogeneousVectors(t reflect.Type) (n int, ok bool) {
+	return homogeneousAggregate(t, isVectorType)
+}
+
+// ensureVectorSupported panics if SIMD vectors cannot be passed to or returned from C
+// on the current platform.
+func ensureVectorSupported() {
+	if runtime.GOOS == "windows" && runtime.GOARCH == "amd64" {
+		panic("purego: Vec128 is not supported on windows/amd64, which passes vectors by reference")
+	}
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		panic("purego: Vec128 is only supported on amd64 and arm64")
+	}
+}
+
+// addVector passes v if it is a Vec128 or a struct holding one that is not passed by reference,
+// and reports whether it did. addQuad places the two halves of a vector in the next vector register.
+// On darwin/arm64 it leaves a value that no longer fits in the vector registers to the stack
+// argument bundling, which aligns it like a vector.
+func addVector(v reflect.Value, addStack func(uintptr), addQuad func(lo, hi uintptr), numFloats, numStack *int) bool {
+	if !containsVector(v.Type()) {
+		return false
+	}
+	n, ok := homogeneousVectors(v.Type())
+	switch runtime.GOARCH {
+	case "amd64":
+		// Only a vector, or a struct of exactly one, is classified SSE and SSEUP and passed in
+		// a vector register. Any other struct holding a vector is passed in memory, which is
+		// aligned to 16 bytes like a vector.
+		if !ok || n != 1 {
+			if *numStack%2 != 0 {
+				addStack(0)
+			}
+			addWords(v, addStack)
+			return true
+		}
+	case "arm64":
+		// A homogeneous aggregate of up to four vectors is passed one per vector register.
+		// Any other struct holding a vector is larger than 16 bytes and passed by reference.
+		if !ok || n > 4 {
+			return false
+		}
+		if isDarwin && *numFloats+n > numOfFloatRegisters() {
+			return false
+		}
+	default:
+		return false
+	}
+	addQuads(v, n, addStack, addQuad, numFloats, numStack)
+	return true
+}
+
+// vectorResult returns the result of type t from syscall if t is a Vec128 or a struct holding one
+// that is returned in vector registers, and reports whether it is.
+func vectorResult(t reflect.Type, syscall *syscallArgs) (reflect.Value, bool) {
+	if !containsVector(t) {
+		return reflect.Value{}, false
+	}
+	n, ok := homogeneousVectors(t)
+	switch {
+	case !ok:
+		return reflect.Value{}, false
+	case runtime.GOARCH == "amd64" && n == 1, runtime.GOARCH == "arm64" && n <= 4:
+		return quadsResult(t, syscall), true
+	}
+	return reflect.Value{}, false
+}
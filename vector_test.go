@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2026 The Ebitengine Authors
+
+//go:build (darwin || linux) && (amd64 || arm64)
+
+package purego_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ebitengine/purego"
+	"github.com/ebitengine/purego/internal/load"
+)
+
+type (
+	float4  = purego.Vec128[float32]
+	double2 = purego.Vec128[float64]
+	int4    = purego.Vec128[int32]
+)
+
+func openVectorTest(t *testing.T) uintptr {
+	t.Helper()
+	libFileName := filepath.Join(t.TempDir(), "vectortest.so")
+	if err := buildSharedLib(t, "CC", libFileName, filepath.Join("testdata", "vectortest", "vector_test.c")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(libFileName) })
+	lib, err := load.OpenLibrary(libFileName)
+	if err != nil {
+		t.Fatalf("Dlopen(%q) failed: %v", libFileName, err)
+	}
+	t.Cleanup(func() { load.CloseLibrary(lib) })
+	return lib
+}
+
+func TestVec128_Elems(t *testing.T) {
+	v := purego.NewVec128[float32](1, 2, 3)
+	if got := v.Elems(); !slices.Equal(got, []float32{1, 2, 3, 0}) {
+		t.Errorf("Elems() = %v, want [1 2 3 0]", got)
+	}
+	v.Elems()[3] = 4
+	if got := v.Elems()[3]; got != 4 {
+		t.Errorf("Elems()[3] = %v after setting it, want 4", got)
+	}
+	bytes := purego.NewVec128[uint8]()
+	if got := len(bytes.Elems()); got != 16 {
+		t.Errorf("len(Elems()) = %d, want 16", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewVec128 with 3 float64 did not panic")
+		}
+	}()
+	purego.NewVec128[float64](1, 2, 3)
+}
+
+type (
+	vectorBox struct {
+		Value float4
+	}
+	vectorPair struct {
+		A, B float4
+	}
+	vectorTagged struct {
+		Tag   int32
+		_     [12]byte
+		Value float4
+	}
+)
+
+func TestVec128(t *testing.T) {
+	lib := openVectorTest(t)
+
+	var addFloat4 func(a, b float4) float4
+	purego.RegisterLibFunc(&addFloat4, lib, "addFloat4")
+	sum := addFloat4(purego.NewVec128[float32](1, 2, 3, 4), purego.NewVec128[float32](10, 20, 30, 40))
+	if got := sum.Elems(); !slices.Equal(got, []float32{11, 22, 33, 44}) {
+		t.Errorf("addFloat4() = %v, want [11 22 33 44]", got)
+	}
+
+	var scaleDouble2 func(s float64, v double2, t float64) double2
+	purego.RegisterLibFunc(&scaleDouble2, lib, "scaleDouble2")
+	scaled := scaleDouble2(2, purego.NewVec128[float64](1.5, -3), 0.25)
+	if got := scaled.Elems(); !slices.Equal(got, []float64{3.25, -5.75}) {
+		t.Errorf("scaleDouble2() = %v, want [3.25 -5.75]", got)
+	}
+
+	var addInt4 func(a, b int4) int4
+	purego.RegisterLibFunc(&addInt4, lib, "addInt4")
+	isum := addInt4(purego.NewVec128[int32](1, -2, 3, -4), purego.NewVec128[int32](100, 200, 300, 400))
+	if got := isum.Elems(); !slices.Equal(got, []int32{101, 198, 303, 396}) {
+		t.Errorf("addInt4() = %v, want [101 198 303 396]", got)
+	}
+
+	var vs [9]float4
+	for i := range vs {
+		f := float32(i + 1)
+		vs[i] = purego.NewVec128(f, f*10, f*100, f*1000)
+	}
+	var sum9 func(a, b, c, d, e, f, g, h, i float4) float32
+	purego.RegisterLibFunc(&sum9, lib, "sum9")
+	if got := sum9(vs[0], vs[1], vs[2], vs[3], vs[4], vs[5], vs[6], vs[7], vs[8]); got != 45*1111 {
+		t.Errorf("sum9() = %v, want %v", got, 45*1111)
+	}
+	var afterOddStack func(a, b, c, d, e, f, g, h float4, s float64, x float4) float32
+	purego.RegisterLibFunc(&afterOddStack, lib, "afterOddStack")
+	if got := afterOddStack(vs[0], vs[1], vs[2], vs[3], vs[4], vs[5], vs[6], vs[7], 0.5, vs[8]); got != 45*1111+0.5 {
+		t.Errorf("afterOddStack() = %v, want %v", got, 45*1111+0.5)
+	}
+
+	var boxSum func(i int32, b vectorBox) float32
+	purego.RegisterLibFunc(&boxSum, lib, "boxSum")
+	if got := boxSum(5, vectorBox{vs[0]}); got != 1116 {
+		t.Errorf("boxSum() = %v, want 1116", got)
+	}
+	var makeBox func(x, y, z, w float32) vectorBox
+	purego.RegisterLibFunc(&makeBox, lib, "makeBox")
+	box := makeBox(1, 2, 3, 4)
+	if got := box.Value.Elems(); !slices.Equal(got, []float32{1, 2, 3, 4}) {
+		t.Errorf("makeBox() = %v, want [1 2 3 4]", got)
+	}
+
+	var pairSum func(p vectorPair) float32
+	purego.RegisterLibFunc(&pairSum, lib, "pairSum")
+	if got := pairSum(vectorPair{vs[0], vs[1]}); got != 3333 {
+		t.Errorf("pairSum() = %v, want 3333", got)
+	}
+	var makePair func(a, b float4) vectorPair
+	purego.RegisterLibFunc(&makePair, lib, "makePair")
+	if p := makePair(vs[0], vs[1]); p != (vectorPair{vs[0], vs[1]}) {
+		t.Errorf("makePair() = {%v, %v}, want {%v, %v}", p.A.Elems(), p.B.Elems(), vs[0].Elems(), vs[1].Elems())
+	}
+
+	if err := purego.CheckLayout(reflect.TypeFor[vectorTagged](), nil); err != nil {
+		t.Errorf("CheckLayout() = %v", err)
+	}
+	var taggedSum func(t vectorTagged) float32
+	purego.RegisterLibFunc(&taggedSum, lib, "taggedSum")
+	if got := taggedSum(vectorTagged{Tag: 5, Value: vs[0]}); got != 1116 {
+		t.Errorf("taggedSum() = %v, want 1116", got)
+	}
+	var makeTagged func(tag int32, value float4) vectorTagged
+	purego.RegisterLibFunc(&makeTagged, lib, "makeTagged")
+	if tg := makeTagged(5, vs[0]); tg.Tag != 5 || tg.Value != vs[0] {
+		t.Errorf("makeTagged() = {%d, %v}, want {5, %v}", tg.Tag, tg.Value.Elems(), vs[0].Elems())
+	}
+}
+
+func TestVec128_Unsupported(t *testing.T) {
+	var fn func(x ...any)
+	purego.RegisterFunc(&fn, 1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("passing a Vec128 in ...any did not panic")
+			}
+		}()
+		fn(purego.NewVec128[float32](1))
+	}()
+	defer func() {
+		if recover() == nil {
+			t.Error("NewCallback with a Vec128 argument did not panic")
+		}
+	}()
+	purego.NewCallback(func(v float4) {})
+}